@@ -0,0 +1,48 @@
+package app
+
+import (
+	"errors"
+	"github.com/goal-web/application"
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/goal/app/exceptions"
+	"github.com/goal-web/goal/app/providers"
+	"github.com/goal-web/goal/config"
+	"github.com/goal-web/supports/logs"
+	"github.com/golang-module/carbon/v2"
+)
+
+// New 按给定角色创建应用，注册对应的服务提供者
+func New(env contracts.Env, profiles ...Profile) contracts.Application {
+	// 常驻服务启动前校验配置，一次列出所有问题，命令行可以通过 config:check 检查
+	if Profiles(profiles).Has(servers...) {
+		if problems := config.Validate(env); len(problems) > 0 {
+			logs.WithError(errors.Join(problems...)).Fatal("goal 配置校验失败!")
+		}
+	}
+
+	app := application.Singleton(env.GetBool("app.debug"))
+
+	// 设置异常处理器
+	app.Singleton("exceptions.handler", func() contracts.ExceptionHandler {
+		return exceptions.NewHandler()
+	})
+
+	services, err := Providers(env, profiles...)
+	if err != nil {
+		logs.WithError(err).Fatal("goal 启动异常!")
+	}
+	app.RegisterServices(services...)
+
+	// 汇总各服务的健康检查
+	app.Singleton("health", func() *providers.Health {
+		return providers.NewHealth(app, services)
+	})
+
+	app.Call(func(config contracts.Config) {
+		appConfig := config.Get("app").(application.Config)
+		carbon.SetLocale(appConfig.Locale)
+		carbon.SetTimezone(appConfig.Timezone)
+	})
+
+	return app
+}
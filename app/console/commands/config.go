@@ -0,0 +1,177 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/goal/config"
+	"github.com/goal-web/supports/commands"
+	"os"
+	"strings"
+)
+
+var ConfigKeyNotFoundErr = errors.New("配置不存在")
+
+// NewConfigCheck 离线校验配置，供部署流水线使用，有问题时以状态码 1 退出
+func NewConfigCheck(app contracts.Application) contracts.Command {
+	return &ConfigCheck{
+		Command: commands.Base("config:check {dir=.} {--env=}", "校验配置，dir 为 config.toml 所在目录，--env 指定 app.env"),
+	}
+}
+
+type ConfigCheck struct {
+	commands.Command
+}
+
+func (cmd ConfigCheck) Handle() any {
+	if appEnv := cmd.GetString("env"); appEnv != "" {
+		_ = os.Setenv(config.EnvPrefix+"APP_ENV", appEnv)
+	}
+
+	env := config.NewEnv(cmd.GetString("dir"))
+	problems := config.Validate(env)
+	if len(problems) == 0 {
+		fmt.Printf("配置校验通过（app.env=%s）\n", env.GetString("app.env"))
+		return nil
+	}
+
+	fmt.Printf("配置有 %d 个问题（app.env=%s）：\n", len(problems), env.GetString("app.env"))
+	for _, problem := range problems {
+		fmt.Println("  - " + problem.Error())
+	}
+	os.Exit(1)
+	return nil
+}
+
+// NewConfigShow 打印解析后的配置，密码、密钥等敏感配置会被隐藏
+func NewConfigShow(app contracts.Application) contracts.Command {
+	return &ConfigShow{
+		Command: commands.Base("config:show {key?} {--format=json}", "打印解析后的配置，key 例如 database、database.Connections.mysql，--format 支持 json、toml"),
+		app:     app,
+	}
+}
+
+type ConfigShow struct {
+	commands.Command
+	app contracts.Application
+}
+
+func (cmd ConfigShow) Handle() any {
+	var (
+		resolved = make(map[string]any)
+		key      = cmd.GetString("key")
+		conf     = cmd.app.Get("config").(contracts.Config)
+	)
+	for name := range config.GetConfigProviders() {
+		resolved[name] = config.Dump(name, conf.Get(name))
+	}
+
+	var result any = resolved
+	if key != "" {
+		value, err := lookup(resolved, key)
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+		// toml 的顶层必须是表，用 key 的最后一段包一层
+		result = map[string]any{key[strings.LastIndex(key, ".")+1:]: value}
+	}
+
+	var buffer bytes.Buffer
+	switch cmd.GetString("format") {
+	case "toml":
+		if err := toml.NewEncoder(&buffer).Encode(result); err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+	default:
+		encoder := json.NewEncoder(&buffer)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(result); err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+	}
+
+	fmt.Print(buffer.String())
+	return nil
+}
+
+// lookup 按点分隔的路径查找配置，忽略大小写
+func lookup(fields map[string]any, key string) (any, error) {
+	var current any = fields
+	for _, segment := range strings.Split(key, ".") {
+		items, isMap := current.(map[string]any)
+		if !isMap {
+			return nil, fmt.Errorf("%w：%s", ConfigKeyNotFoundErr, key)
+		}
+		var found bool
+		for name, item := range items {
+			if strings.EqualFold(name, segment) {
+				current, found = item, true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%w：%s", ConfigKeyNotFoundErr, key)
+		}
+	}
+	return current, nil
+}
+
+// NewConfigEncrypt 加密配置值，输出可以直接写入 config.toml 的 enc: 密文
+func NewConfigEncrypt(app contracts.Application) contracts.Command {
+	return &ConfigEncrypt{
+		Command: commands.Base("config:encrypt {value} {--key=}", "加密配置值，密钥默认取环境变量 GOAL_CONFIG_KEY"),
+	}
+}
+
+type ConfigEncrypt struct {
+	commands.Command
+}
+
+func (cmd ConfigEncrypt) Handle() any {
+	encryptor, err := config.NewEncryptor(cmd.GetString("key"))
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+
+	fmt.Println(config.Encrypt(encryptor, cmd.GetString("value")))
+	return nil
+}
+
+// NewConfigDecrypt 解密 enc: 开头的配置值
+func NewConfigDecrypt(app contracts.Application) contracts.Command {
+	return &ConfigDecrypt{
+		Command: commands.Base("config:decrypt {value} {--key=}", "解密配置值，密钥默认取环境变量 GOAL_CONFIG_KEY"),
+	}
+}
+
+type ConfigDecrypt struct {
+	commands.Command
+}
+
+func (cmd ConfigDecrypt) Handle() any {
+	encryptor, err := config.NewEncryptor(cmd.GetString("key"))
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+
+	value := cmd.GetString("value")
+	if !strings.HasPrefix(value, config.EncryptedPrefix) {
+		value = config.EncryptedPrefix + value
+	}
+	plaintext, err := config.Decrypt(encryptor, value)
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+
+	fmt.Println(plaintext)
+	return nil
+}
@@ -0,0 +1,190 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/goal/app/keyring"
+	"github.com/goal-web/supports/commands"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var KeyNotWrittenErr = errors.New("无法写入应用密钥")
+
+// NewKeyGenerate 生成新的应用密钥，原来的密钥移入 app.previous_keys，旧密钥加密和签名的数据仍然可以解开
+func NewKeyGenerate(app contracts.Application) contracts.Command {
+	return &KeyGenerate{
+		Command: commands.Base("key:generate {dir=.} {--file=} {--keep=3} {--show}",
+			"生成应用密钥，默认写入 .env（没有配置 app.key 时写入 config.toml），--keep 为保留的旧密钥数量，--show 只打印不写入"),
+	}
+}
+
+type KeyGenerate struct {
+	commands.Command
+}
+
+func (cmd KeyGenerate) Handle() any {
+	key := keyring.Generate()
+	if cmd.GetBool("show") {
+		fmt.Println(key)
+		return nil
+	}
+
+	path := cmd.GetString("file")
+	if path == "" {
+		path = keyFile(cmd.GetString("dir"))
+	}
+	previous, err := writeKey(path, key, cmd.GetInt("keep"))
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+
+	fmt.Printf("新的应用密钥已写入 %s\n", path)
+	if len(previous) > 0 {
+		fmt.Printf("原来的密钥已移入 app.previous_keys（共 %d 个），旧数据重新加密后可以删除\n", len(previous))
+	}
+	fmt.Println("重启或者执行 reload 后生效")
+	return nil
+}
+
+// keyFile .env 中配置了 app.key 时写入 .env，否则写入 config.toml
+func keyFile(dir string) string {
+	dotenv := filepath.Join(dir, ".env")
+	if content, err := os.ReadFile(dotenv); err == nil {
+		if _, exists := keyLines(dotenv, strings.Split(string(content), "\n")).get("key"); exists {
+			return dotenv
+		}
+	}
+	return filepath.Join(dir, "config.toml")
+}
+
+// writeKey 写入新密钥，返回写入后的旧密钥
+func writeKey(path, key string, keep int) ([]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w：%v", KeyNotWrittenErr, err)
+	}
+
+	var (
+		lines    = strings.Split(string(content), "\n")
+		section  = keyLines(path, lines)
+		previous = make([]string, 0)
+	)
+	if current, _ := section.get("key"); current != "" {
+		previous = append(previous, current)
+	}
+	keys, hasPrevious := section.get("previous_keys")
+	if keys != "" {
+		for _, item := range strings.Split(keys, ",") {
+			if item = strings.TrimSpace(item); item != "" && item != key {
+				previous = append(previous, item)
+			}
+		}
+	}
+	if keep >= 0 && len(previous) > keep {
+		previous = previous[:keep]
+	}
+
+	lines = section.set(lines, "key", key)
+	if hasPrevious || len(previous) > 0 {
+		lines = keyLines(path, lines).set(lines, "previous_keys", strings.Join(previous, ","))
+	}
+
+	stat, err := os.Stat(path)
+	perm := os.FileMode(0644)
+	if err == nil {
+		perm = stat.Mode().Perm()
+	}
+	if err = os.WriteFile(path, []byte(strings.Join(lines, "\n")), perm); err != nil {
+		return nil, fmt.Errorf("%w：%v", KeyNotWrittenErr, err)
+	}
+	return previous, nil
+}
+
+// keySection 文件中 app 配置所在的行，.env 中为 app.key=xxx，toml 中为 [app] 下的 key = "xxx"
+type keySection struct {
+	toml       bool
+	start, end int
+	lines      []string
+}
+
+func keyLines(path string, lines []string) keySection {
+	var section = keySection{toml: strings.HasSuffix(path, ".toml"), end: len(lines), lines: lines}
+	if !section.toml {
+		return section
+	}
+
+	section.start, section.end = -1, -1
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "[") {
+			continue
+		}
+		if section.start >= 0 {
+			section.end = i
+			break
+		}
+		if line == "[app]" {
+			section.start = i + 1
+		}
+	}
+	if section.start < 0 { // 没有 [app] 时追加到末尾
+		section.start = len(lines)
+	}
+	if section.end < 0 {
+		section.end = len(lines)
+	}
+	return section
+}
+
+func (section keySection) name(key string) string {
+	if section.toml {
+		return key
+	}
+	return "app." + key
+}
+
+// find 返回配置所在的行号以及值
+func (section keySection) find(key string) (int, string) {
+	for i := section.start; i < section.end; i++ {
+		name, value, found := strings.Cut(section.lines[i], "=")
+		if found && strings.TrimSpace(name) == section.name(key) {
+			return i, strings.Trim(strings.TrimSpace(value), `"'`)
+		}
+	}
+	return -1, ""
+}
+
+func (section keySection) get(key string) (string, bool) {
+	index, value := section.find(key)
+	return value, index >= 0
+}
+
+// set 替换或者插入配置所在的行，返回新的行
+func (section keySection) set(lines []string, key, value string) []string {
+	var entry = section.name(key) + "=" + value
+	if section.toml {
+		entry = fmt.Sprintf("%s = %q", key, value)
+	}
+
+	index, _ := section.find(key)
+	switch {
+	case index >= 0:
+		lines[index] = entry
+		return lines
+	case section.toml && section.start == len(lines):
+		return append(lines, "", "[app]", entry)
+	default:
+		// 插入到 app 配置的最后一行之后，没有 app 配置时插入到段首
+		at := section.start
+		for i := section.start; i < section.end; i++ {
+			if line := strings.TrimSpace(lines[i]); line != "" && (section.toml || strings.HasPrefix(line, section.name(""))) {
+				at = i + 1
+			}
+		}
+		return append(lines[:at], append([]string{entry}, lines[at:]...)...)
+	}
+}
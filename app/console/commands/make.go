@@ -0,0 +1,515 @@
+package commands
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/supports/commands"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	InvalidNameErr   = errors.New("名称有误，只能包含字母、数字和下划线，并以字母开头")
+	FileExistsErr    = errors.New("文件已存在，使用 --force 覆盖")
+	EventNotFoundErr = errors.New("事件不存在")
+	RegisterErr      = errors.New("自动注册失败，请手动注册")
+)
+
+// stub 生成的文件，Name 为类型名，其余字段由各生成器的参数决定
+type stub struct {
+	Name      string // 类型名，例如 SendEmail
+	Signature string // 命令签名
+	Table     string // 模型的表名
+	Event     string // 监听器监听的事件
+}
+
+// generator 代码生成器，文件生成在 dir 下，文件名为名称的下划线形式
+type generator struct {
+	dir      string
+	template *template.Template
+	prepare  func(cmd Make, stub *stub) error
+	register func(stub stub) (string, error) // 自动注册，返回注册到的文件
+}
+
+// NewMakeController 生成控制器，路由需要自己在 routes 中注册
+func NewMakeController(app contracts.Application) contracts.Command {
+	return newMake("make:controller {name} {--force}", "生成控制器", generator{
+		dir:      "app/http/controllers",
+		template: stubTemplate(controllerStub),
+	})
+}
+
+// NewMakeJob 生成队列任务，并注册到 config/serialization.go 中
+func NewMakeJob(app contracts.Application) contracts.Command {
+	return newMake("make:job {name} {--force}", "生成队列任务，并注册到序列化配置", generator{
+		dir:      "app/jobs",
+		template: stubTemplate(jobStub),
+		register: func(stub stub) (string, error) {
+			return "config/serialization.go", appendElement("config/serialization.go", "[]contracts.Class[any]", "jobs."+stub.Name+"Class")
+		},
+	})
+}
+
+// NewMakeListener 生成事件监听器，指定 --event 时注册到 providers.NewEvents 中，事件为 app/events 中的类型
+func NewMakeListener(app contracts.Application) contracts.Command {
+	return newMake("make:listener {name} {--event=} {--force}", "生成事件监听器，--event 为 app/events 中的事件，指定时自动注册", generator{
+		dir:      "app/listeners",
+		template: stubTemplate(listenerStub),
+		prepare: func(cmd Make, stub *stub) error {
+			if event := cmd.GetString("event"); event != "" {
+				if stub.Event = studly(event); !eventExists(stub.Event) {
+					return fmt.Errorf("%w：events.%s", EventNotFoundErr, stub.Event)
+				}
+				content, _ := os.ReadFile("app/providers/events.go")
+				if strings.Contains(string(content), "&events."+stub.Event+"{}:") {
+					// 框架的事件分发器只会执行同一事件的最后一个监听器，见 listeners.ApplyConfig
+					return fmt.Errorf("%w：events.%s 已经有监听器，请在已有的监听器中调用", RegisterErr, stub.Event)
+				}
+			}
+			return nil
+		},
+		register: func(stub stub) (string, error) {
+			if stub.Event == "" {
+				return "", nil
+			}
+			return "app/providers/events.go", appendElement("app/providers/events.go", "map[contracts.Event][]contracts.EventListener",
+				fmt.Sprintf("&events.%s{}: {listeners.%s{}}", stub.Event, stub.Name))
+		},
+	})
+}
+
+// NewMakeCommand 生成命令，并注册到 console.NewKernel 中
+func NewMakeCommand(app contracts.Application) contracts.Command {
+	return newMake("make:command {name} {--command=} {--force}", "生成命令，并注册到 console kernel，--command 为命令名，默认由名称生成，例如 SendEmails 为 send:emails", generator{
+		dir:      "app/console/commands",
+		template: stubTemplate(commandStub),
+		prepare: func(cmd Make, stub *stub) error {
+			stub.Signature = cmd.GetString("command")
+			if stub.Signature == "" {
+				stub.Signature = strings.ReplaceAll(snake(stub.Name), "_", ":")
+			}
+			return nil
+		},
+		register: func(stub stub) (string, error) {
+			return "app/console/kernel.go", appendElement("app/console/kernel.go", "[]contracts.CommandProvider", "commands.New"+stub.Name)
+		},
+	})
+}
+
+// NewMakePolicy 生成权限策略
+func NewMakePolicy(app contracts.Application) contracts.Command {
+	return newMake("make:policy {name} {--force}", "生成权限策略", generator{
+		dir:      "app/policies",
+		template: stubTemplate(policyStub),
+	})
+}
+
+// NewMakeRequest 生成表单请求
+func NewMakeRequest(app contracts.Application) contracts.Command {
+	return newMake("make:request {name} {--force}", "生成表单请求", generator{
+		dir:      "app/http/requests",
+		template: stubTemplate(requestStub),
+	})
+}
+
+// NewMakeMiddleware 生成中间件
+func NewMakeMiddleware(app contracts.Application) contracts.Command {
+	return newMake("make:middleware {name} {--force}", "生成中间件", generator{
+		dir:      "app/http/middlewares",
+		template: stubTemplate(middlewareStub),
+	})
+}
+
+// NewMakeModel 生成模型，--table 默认为名称的复数形式，例如 Comment 为 comments
+func NewMakeModel(app contracts.Application) contracts.Command {
+	return newMake("make:model {name} {--table=} {--force}", "生成模型，--table 为表名", generator{
+		dir:      "app/models",
+		template: stubTemplate(modelStub),
+		prepare: func(cmd Make, stub *stub) error {
+			if stub.Table = cmd.GetString("table"); stub.Table == "" {
+				stub.Table = plural(snake(stub.Name))
+			}
+			return nil
+		},
+	})
+}
+
+func newMake(signature, description string, generator generator) contracts.Command {
+	return &Make{
+		Command:   commands.Base(signature, description),
+		generator: generator,
+	}
+}
+
+// Make 代码生成命令，需要在项目根目录执行
+type Make struct {
+	commands.Command
+	generator generator
+}
+
+func (cmd Make) Handle() any {
+	path, registered, err := cmd.generate()
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	fmt.Println("已生成 " + path)
+	if registered != "" {
+		fmt.Println("已注册到 " + registered)
+	}
+	return nil
+}
+
+// generate 返回生成的文件以及注册到的文件，注册失败时还原生成的文件
+func (cmd Make) generate() (string, string, error) {
+	var (
+		name     = studly(cmd.GetString("name"))
+		first, _ = utf8.DecodeRuneInString(name)
+	)
+	if !unicode.IsLetter(first) || !token.IsIdentifier(name) {
+		return "", "", fmt.Errorf("%w：%s", InvalidNameErr, cmd.GetString("name"))
+	}
+
+	var (
+		data = stub{Name: name}
+		path = filepath.Join(cmd.generator.dir, snake(name)+".go")
+	)
+	previous, readErr := os.ReadFile(path)
+	if readErr == nil && !cmd.GetBool("force") {
+		return "", "", fmt.Errorf("%w：%s", FileExistsErr, path)
+	}
+	if cmd.generator.prepare != nil {
+		if err := cmd.generator.prepare(cmd, &data); err != nil {
+			return "", "", err
+		}
+	}
+
+	var buffer bytes.Buffer
+	if err := cmd.generator.template.Execute(&buffer, data); err != nil {
+		return "", "", err
+	}
+	source, err := format.Source(buffer.Bytes())
+	if err != nil {
+		return "", "", err
+	}
+	if err = os.MkdirAll(cmd.generator.dir, os.ModePerm); err != nil {
+		return "", "", err
+	}
+	if err = os.WriteFile(path, source, 0644); err != nil {
+		return "", "", err
+	}
+
+	if cmd.generator.register == nil {
+		return path, "", nil
+	}
+	registered, err := cmd.generator.register(data)
+	if err != nil {
+		if readErr == nil {
+			_ = os.WriteFile(path, previous, 0644)
+		} else {
+			_ = os.Remove(path)
+		}
+		return "", "", err
+	}
+	return path, registered, nil
+}
+
+// appendElement 在文件中第一个类型为 literalType 的复合字面量末尾追加一个元素，已经存在时跳过，map 中已经有同一个键时返回错误
+// 通过语法树定位列表和比较元素，不依赖列表前后的代码格式
+func appendElement(path, literalType, element string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("%w：%v", RegisterErr, err)
+	}
+	// 元素可能是键值对，放到复合字面量中解析
+	wrapper, err := parser.ParseExpr(literalType + "{" + element + "}")
+	if err != nil {
+		return fmt.Errorf("%w：%v", RegisterErr, err)
+	}
+	var (
+		fileSet = token.NewFileSet()
+		literal *ast.CompositeLit
+		added   = wrapper.(*ast.CompositeLit).Elts[0]
+	)
+	file, err := parser.ParseFile(fileSet, path, content, 0)
+	if err != nil {
+		return fmt.Errorf("%w：%v", RegisterErr, err)
+	}
+	ast.Inspect(file, func(node ast.Node) bool {
+		if found, ok := node.(*ast.CompositeLit); ok && literal == nil && found.Type != nil && types.ExprString(found.Type) == literalType {
+			literal = found
+		}
+		return literal == nil
+	})
+	if literal == nil {
+		return fmt.Errorf("%w：%s 中没有找到注册列表 %s", RegisterErr, path, literalType)
+	}
+	for _, existing := range literal.Elts {
+		// map 的键不能重复，ExprString 会省略复合字面量的内容，所以只比较键
+		pair, isPair := existing.(*ast.KeyValueExpr)
+		if addedPair, ok := added.(*ast.KeyValueExpr); ok {
+			if isPair && types.ExprString(pair.Key) == types.ExprString(addedPair.Key) {
+				return fmt.Errorf("%w：%s 中已经注册了 %s", RegisterErr, path, types.ExprString(addedPair.Key))
+			}
+			continue
+		}
+		if types.ExprString(existing) == types.ExprString(added) {
+			return nil
+		}
+	}
+
+	var (
+		end       = fileSet.Position(literal.Rbrace).Offset
+		insertion = element + ",\n"
+		result    bytes.Buffer
+	)
+	if count := len(literal.Elts); count > 0 {
+		last := fileSet.Position(literal.Elts[count-1].End()).Offset
+		if !bytes.Contains(content[last:end], []byte(",")) {
+			insertion = ",\n" + insertion
+		}
+	}
+	result.Write(content[:end])
+	result.WriteString(insertion)
+	result.Write(content[end:])
+
+	source, err := format.Source(result.Bytes())
+	if err != nil {
+		return fmt.Errorf("%w：%v", RegisterErr, err)
+	}
+	return os.WriteFile(path, source, 0644)
+}
+
+// eventExists app/events 中是否声明了该事件
+func eventExists(name string) bool {
+	files, _ := filepath.Glob("app/events/*.go")
+	for _, file := range files {
+		if content, err := os.ReadFile(file); err == nil && bytes.Contains(content, []byte("type "+name+" struct")) {
+			return true
+		}
+	}
+	return false
+}
+
+// words 按非字母数字以及大小写边界拆分名称，例如 send_email、SendEmail 都拆分为 send、email
+func words(name string) []string {
+	var (
+		results []string
+		current []rune
+		runes   = []rune(name)
+	)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if len(current) > 0 {
+				results, current = append(results, string(current)), nil
+			}
+			continue
+		}
+		// 大写字母开始一个新单词，连续的大写（例如 HTTPServer）只在最后一个大写字母前拆分
+		if unicode.IsUpper(r) && len(current) > 0 &&
+			(!unicode.IsUpper(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+			results, current = append(results, string(current)), nil
+		}
+		current = append(current, r)
+	}
+	if len(current) > 0 {
+		results = append(results, string(current))
+	}
+	return results
+}
+
+// studly 转换为类型名，例如 send_email 为 SendEmail
+func studly(name string) string {
+	var result strings.Builder
+	for _, word := range words(name) {
+		first, size := utf8.DecodeRuneInString(word)
+		result.WriteString(string(unicode.ToUpper(first)) + word[size:])
+	}
+	return result.String()
+}
+
+// snake 转换为文件名，例如 SendEmail 为 send_email
+func snake(name string) string {
+	var items = words(name)
+	for i, word := range items {
+		items[i] = strings.ToLower(word)
+	}
+	return strings.Join(items, "_")
+}
+
+// plural 简单的英文复数规则，不满足时使用 --table 指定
+func plural(word string) string {
+	switch {
+	case len(word) > 1 && strings.HasSuffix(word, "y") && !strings.ContainsAny(word[len(word)-2:len(word)-1], "aeiou"):
+		return word[:len(word)-1] + "ies"
+	case strings.HasSuffix(word, "s"), strings.HasSuffix(word, "x"), strings.HasSuffix(word, "ch"), strings.HasSuffix(word, "sh"):
+		return word + "es"
+	default:
+		return word + "s"
+	}
+}
+
+func stubTemplate(content string) *template.Template {
+	return template.Must(template.New("stub").Parse(content))
+}
+
+const controllerStub = `package controllers
+
+import (
+	"github.com/goal-web/contracts"
+)
+
+func {{.Name}}(request contracts.HttpRequest) any {
+	return contracts.Fields{}
+}
+`
+
+const jobStub = `package jobs
+
+import (
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/queue"
+	"github.com/goal-web/supports/class"
+	"github.com/goal-web/supports/utils"
+	"time"
+)
+
+var {{.Name}}Class = class.Any({{.Name}}{})
+
+type {{.Name}} struct {
+	*queue.Job
+}
+
+func New{{.Name}}() contracts.Job {
+	return &{{.Name}}{
+		Job: &queue.Job{
+			UUID:       utils.RandStr(5),
+			CreatedAt:  time.Now().Unix(),
+			Queue:      "default",
+			Connection: "default",
+			Tries:      0,
+			MaxTries:   3,
+			Timeout:    0,
+		},
+	}
+}
+
+func (job *{{.Name}}) Handle() {
+
+}
+`
+
+const listenerStub = `package listeners
+
+import (
+	"github.com/goal-web/contracts"
+{{- if .Event}}
+	"github.com/goal-web/goal/app/events"
+{{- end}}
+)
+
+type {{.Name}} struct {
+}
+
+func (listener {{.Name}}) Handle(event contracts.Event) {
+{{- if .Event}}
+	if _, ok := event.(*events.{{.Event}}); ok {
+
+	}
+{{- end}}
+}
+`
+
+const commandStub = `package commands
+
+import (
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/supports/commands"
+)
+
+func New{{.Name}}(app contracts.Application) contracts.Command {
+	return &{{.Name}}{
+		Command: commands.Base("{{.Signature}}", ""),
+	}
+}
+
+type {{.Name}} struct {
+	commands.Command
+}
+
+func (cmd {{.Name}}) Handle() any {
+	return nil
+}
+`
+
+const policyStub = `package policies
+
+import (
+	"github.com/goal-web/contracts"
+)
+
+var {{.Name}} contracts.Policy = map[string]contracts.GateChecker{
+	"create": func(authorizable contracts.Authorizable, data ...any) bool {
+		return false
+	},
+	"update": func(authorizable contracts.Authorizable, data ...any) bool {
+		return false
+	},
+	"delete": func(authorizable contracts.Authorizable, data ...any) bool {
+		return false
+	},
+}
+`
+
+const requestStub = `package requests
+
+import "github.com/goal-web/contracts"
+
+type {{.Name}} struct {
+	contracts.HttpRequest ` + "`di:\"\"`" + ` // 加入 di 标记表示需要注入
+}
+
+func (r {{.Name}}) Rules() contracts.Fields {
+	return contracts.Fields{}
+}
+`
+
+const middlewareStub = `package middlewares
+
+import (
+	"github.com/goal-web/contracts"
+)
+
+func {{.Name}}(request contracts.HttpRequest, next contracts.Pipe) any {
+	return next(request)
+}
+`
+
+const modelStub = `package models
+
+import (
+	"github.com/goal-web/database/table"
+	"github.com/goal-web/supports/class"
+)
+
+var {{.Name}}Class = class.Make[{{.Name}}]()
+
+func {{.Name}}Query() *table.Table[{{.Name}}] {
+	return table.Class({{.Name}}Class, "{{.Table}}")
+}
+
+type {{.Name}} struct {
+	Id string ` + "`json:\"id\"`" + `
+}
+`
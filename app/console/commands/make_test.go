@@ -0,0 +1,185 @@
+package commands
+
+import (
+	"errors"
+	"github.com/goal-web/console/arguments"
+	"github.com/goal-web/contracts"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const kernelSource = `package console
+
+var commands = []contracts.CommandProvider{
+	commands.NewHello,
+	commands.NewRunner, // 运行
+}
+`
+
+const eventsSource = `package providers
+
+var listeners = map[contracts.Event][]contracts.EventListener{
+	&events.UserSignUp{}: {listeners.SendWelcomeEmail{}},
+}
+`
+
+func TestAppendElement(t *testing.T) {
+	cases := []struct {
+		name        string
+		source      string
+		literalType string
+		element     string
+		contains    []string
+		err         error
+	}{
+		{
+			name:        "append to a list",
+			source:      kernelSource,
+			literalType: "[]contracts.CommandProvider",
+			element:     "commands.NewSendEmails",
+			contains:    []string{"commands.NewRunner, // 运行\n", "commands.NewSendEmails,\n}"},
+		},
+		{
+			name:        "append to an empty list",
+			source:      "package console\n\nvar commands = []contracts.CommandProvider{}\n",
+			literalType: "[]contracts.CommandProvider",
+			element:     "commands.NewSendEmails",
+			contains:    []string{"{commands.NewSendEmails}"},
+		},
+		{
+			name:        "append to a list without a trailing comma",
+			source:      "package console\n\nvar commands = []contracts.CommandProvider{commands.NewHello}\n",
+			literalType: "[]contracts.CommandProvider",
+			element:     "commands.NewSendEmails",
+			contains:    []string{"commands.NewHello,", "commands.NewSendEmails,"},
+		},
+		{
+			name:        "registered elements are skipped",
+			source:      kernelSource,
+			literalType: "[]contracts.CommandProvider",
+			element:     "commands.NewRunner",
+			contains:    []string{kernelSource},
+		},
+		{
+			name:        "commented out elements are not registered",
+			source:      "package console\n\nvar commands = []contracts.CommandProvider{\n\tcommands.NewHello,\n\t// commands.NewSendEmails,\n}\n",
+			literalType: "[]contracts.CommandProvider",
+			element:     "commands.NewSendEmails",
+			contains:    []string{"// commands.NewSendEmails,\n\tcommands.NewSendEmails,\n}"},
+		},
+		{
+			name:        "append to a map",
+			source:      eventsSource,
+			literalType: "map[contracts.Event][]contracts.EventListener",
+			element:     "&events.OrderPaid{}: {listeners.SendReceipt{}}",
+			contains:    []string{"{listeners.SendWelcomeEmail{}},\n", "{listeners.SendReceipt{}},\n}"},
+		},
+		{
+			name:        "registered map keys are rejected",
+			source:      eventsSource,
+			literalType: "map[contracts.Event][]contracts.EventListener",
+			element:     "&events.UserSignUp{}: {listeners.Audit{}}",
+			contains:    []string{eventsSource},
+			err:         RegisterErr,
+		},
+		{
+			name:        "no registration list",
+			source:      "package console\n",
+			literalType: "[]contracts.CommandProvider",
+			element:     "commands.NewSendEmails",
+			contains:    []string{"package console\n"},
+			err:         RegisterErr,
+		},
+	}
+	for _, item := range cases {
+		t.Run(item.name, func(t *testing.T) {
+			var path = filepath.Join(t.TempDir(), "file.go")
+			if err := os.WriteFile(path, []byte(item.source), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := appendElement(path, item.literalType, item.element); !errors.Is(err, item.err) {
+				t.Fatalf("err = %v, want %v", err, item.err)
+			}
+			content, _ := os.ReadFile(path)
+			for _, expected := range item.contains {
+				if !strings.Contains(string(content), expected) {
+					t.Errorf("file does not contain %q:\n%s", expected, content)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateName(t *testing.T) {
+	cases := []struct {
+		name     string
+		input    string
+		expected string
+		err      error
+	}{
+		{"snake case", "send_email", "send_email.go", nil},
+		{"studly case", "SendEmail", "send_email.go", nil},
+		{"non ascii letters", "émail", "émail.go", nil},
+		{"empty", "", "", InvalidNameErr},
+		{"only separators", "__", "", InvalidNameErr},
+		{"starts with a digit", "1st_email", "", InvalidNameErr},
+	}
+	for _, item := range cases {
+		t.Run(item.name, func(t *testing.T) {
+			var (
+				dir = t.TempDir()
+				cmd = newMake("make:controller {name?} {--force}", "", generator{
+					dir:      dir,
+					template: stubTemplate(controllerStub),
+				}).(*Make)
+			)
+			if err := cmd.InjectArguments(arguments.NewArguments([]string{item.input}, contracts.Fields{})); err != nil {
+				t.Fatal(err)
+			}
+			path, _, err := cmd.generate()
+			if !errors.Is(err, item.err) {
+				t.Fatalf("err = %v, want %v", err, item.err)
+			}
+			if err == nil && path != filepath.Join(dir, item.expected) {
+				t.Errorf("path = %q, want %q", path, filepath.Join(dir, item.expected))
+			}
+		})
+	}
+}
+
+func TestNames(t *testing.T) {
+	cases := []struct {
+		input  string
+		studly string
+		snake  string
+		plural string
+	}{
+		{"send_email", "SendEmail", "send_email", "send_emails"},
+		{"SendEmail", "SendEmail", "send_email", "send_emails"},
+		{"send-email", "SendEmail", "send_email", "send_emails"},
+		{"HTTPServer", "HTTPServer", "http_server", "http_servers"},
+		{"user2fa", "User2fa", "user2fa", "user2fas"},
+		{"category", "Category", "category", "categories"},
+		{"day", "Day", "day", "days"},
+		{"y", "Y", "y", "ys"},
+		{"address", "Address", "address", "addresses"},
+		{"box", "Box", "box", "boxes"},
+		{"batch", "Batch", "batch", "batches"},
+		{"wish", "Wish", "wish", "wishes"},
+	}
+	for _, item := range cases {
+		t.Run(item.input, func(t *testing.T) {
+			if value := studly(item.input); value != item.studly {
+				t.Errorf("studly = %q, want %q", value, item.studly)
+			}
+			if value := snake(item.input); value != item.snake {
+				t.Errorf("snake = %q, want %q", value, item.snake)
+			}
+			if value := plural(snake(item.input)); value != item.plural {
+				t.Errorf("plural = %q, want %q", value, item.plural)
+			}
+		})
+	}
+}
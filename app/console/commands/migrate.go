@@ -0,0 +1,147 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"github.com/goal-web/application"
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/goal/app/migration"
+	"github.com/goal-web/goal/database/migrations"
+	"github.com/goal-web/supports/commands"
+	"os"
+	"text/tabwriter"
+)
+
+var ProductionErr = errors.New("当前为生产环境，请使用 --force 确认")
+
+// NewMigrate 执行 database/migrations 中未执行的迁移
+func NewMigrate(app contracts.Application) contracts.Command {
+	return &Migrate{
+		Command: commands.Base("migrate {--connection=} {--step}", "执行迁移，--connection 为 config/database.go 中的连接，--step 每个迁移单独一个批次"),
+		app:     app,
+	}
+}
+
+type Migrate struct {
+	commands.Command
+	app contracts.Application
+}
+
+func (cmd Migrate) Handle() any {
+	migrator := newMigrator(cmd.app, cmd.GetString("connection"))
+	names, err := migrator.Run(cmd.GetBool("step"))
+	printMigrations("已执行", names)
+	exitOnError(err)
+	if len(names) == 0 {
+		fmt.Println("没有需要执行的迁移")
+	}
+	return nil
+}
+
+// NewMigrateRollback 回滚最后一个批次，或者最后 --step 个迁移
+func NewMigrateRollback(app contracts.Application) contracts.Command {
+	return &MigrateRollback{
+		Command: commands.Base("migrate:rollback {--connection=} {--step=0} {--force}", "回滚最后一个批次的迁移，--step 指定回滚的迁移数量"),
+		app:     app,
+	}
+}
+
+type MigrateRollback struct {
+	commands.Command
+	app contracts.Application
+}
+
+func (cmd MigrateRollback) Handle() any {
+	confirmProduction(cmd.app, cmd.GetBool("force"))
+	migrator := newMigrator(cmd.app, cmd.GetString("connection"))
+	names, err := migrator.Rollback(cmd.GetInt("step"))
+	printMigrations("已回滚", names)
+	exitOnError(err)
+	if len(names) == 0 {
+		fmt.Println("没有需要回滚的迁移")
+	}
+	return nil
+}
+
+// NewMigrateStatus 列出所有迁移以及执行情况
+func NewMigrateStatus(app contracts.Application) contracts.Command {
+	return &MigrateStatus{
+		Command: commands.Base("migrate:status {--connection=}", "列出所有迁移以及执行情况"),
+		app:     app,
+	}
+}
+
+type MigrateStatus struct {
+	commands.Command
+	app contracts.Application
+}
+
+func (cmd MigrateStatus) Handle() any {
+	statuses, err := newMigrator(cmd.app, cmd.GetString("connection")).Status()
+	exitOnError(err)
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(writer, "RAN\tBATCH\tMIGRATION")
+	for _, status := range statuses {
+		ran, batch := "No", "-"
+		if status.Ran() {
+			ran, batch = "Yes", fmt.Sprint(status.Batch)
+		}
+		_, _ = fmt.Fprintf(writer, "%s\t%s\t%s\n", ran, batch, status.Name)
+	}
+	_ = writer.Flush()
+	return nil
+}
+
+// NewMigrateFresh 删除所有表后重新执行所有迁移
+func NewMigrateFresh(app contracts.Application) contracts.Command {
+	return &MigrateFresh{
+		Command: commands.Base("migrate:fresh {--connection=} {--seed} {--force}", "删除所有表后重新执行所有迁移，--seed 之后执行 DatabaseSeeder"),
+		app:     app,
+	}
+}
+
+type MigrateFresh struct {
+	commands.Command
+	app contracts.Application
+}
+
+func (cmd MigrateFresh) Handle() any {
+	confirmProduction(cmd.app, cmd.GetBool("force"))
+	names, err := newMigrator(cmd.app, cmd.GetString("connection")).Fresh()
+	if err == nil || len(names) > 0 {
+		fmt.Println("已删除所有表")
+	}
+	printMigrations("已执行", names)
+	exitOnError(err)
+	if cmd.GetBool("seed") {
+		exitOnError(seed("DatabaseSeeder", cmd.GetString("connection")))
+	}
+	return nil
+}
+
+func newMigrator(app contracts.Application, connection string) *migration.Migrator {
+	migrator, err := migration.NewMigrator(app.Get("db.factory").(contracts.DBFactory).Connection(connection), migrations.All())
+	exitOnError(err)
+	return migrator
+}
+
+// confirmProduction 生产环境中删除数据的命令需要 --force
+func confirmProduction(app contracts.Application, force bool) {
+	if !force && app.Get("config").(contracts.Config).Get("app").(application.Config).Env == "production" {
+		exitOnError(ProductionErr)
+	}
+}
+
+func printMigrations(action string, names []string) {
+	for _, name := range names {
+		fmt.Printf("%s：%s\n", action, name)
+	}
+}
+
+func exitOnError(err error) {
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+}
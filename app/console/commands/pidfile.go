@@ -0,0 +1,111 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/goal/config"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+var (
+	AlreadyRunningErr = errors.New("goal 已经在运行")
+	NotRunningErr     = errors.New("goal 没有运行")
+)
+
+// pidFile 加锁的 pid 文件，进程存活期间一直持有锁，进程退出后锁自动释放
+// 因此文件存在但没有被锁住时说明是上次异常退出留下的
+type pidFile struct {
+	path string
+	file *os.File
+}
+
+// pidConfig 获取 pid 文件配置
+func pidConfig(app contracts.Application) config.PidConfig {
+	return app.Get("config").(contracts.Config).Get("pid").(config.PidConfig)
+}
+
+// lockPid 创建并锁住 pid 文件，已有实例在运行时返回 AlreadyRunningErr
+func lockPid(path string) (*pidFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err = syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		_ = file.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			pid, _ := readPid(path)
+			return nil, fmt.Errorf("%w：pid %d（%s）", AlreadyRunningErr, pid, path)
+		}
+		return nil, err
+	}
+
+	if err = file.Truncate(0); err == nil {
+		_, err = file.WriteAt([]byte(strconv.Itoa(os.Getpid())), 0)
+	}
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	return &pidFile{path: path, file: file}, nil
+}
+
+// release 删除 pid 文件并释放锁
+func (pid *pidFile) release() {
+	_ = os.Remove(pid.path)
+	_ = pid.file.Close()
+}
+
+func readPid(path string) (int, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(content)))
+}
+
+// runningPid 获取正在运行的实例的 pid，pid 文件不存在或者已经失效时返回 NotRunningErr
+func runningPid(path string) (int, error) {
+	file, err := os.OpenFile(path, os.O_RDONLY, 0)
+	if os.IsNotExist(err) {
+		return 0, NotRunningErr
+	} else if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	// 能拿到锁说明持有锁的进程已经退出
+	if err = syscall.Flock(int(file.Fd()), syscall.LOCK_SH|syscall.LOCK_NB); err == nil {
+		_ = syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+		return 0, fmt.Errorf("%w：%s 已失效", NotRunningErr, path)
+	}
+
+	return readPid(path)
+}
+
+// pidFiles 获取给定角色的 pid 文件，没有指定角色时返回所有已存在的 pid 文件
+func pidFiles(app contracts.Application, role string) map[string]string {
+	var (
+		conf  = pidConfig(app)
+		files = make(map[string]string)
+	)
+	if role != "" {
+		files[role] = conf.Role(role)
+		return files
+	}
+
+	prefix, suffix, _ := strings.Cut(conf.Path, "{role}")
+	matches, _ := filepath.Glob(conf.Role("*"))
+	for _, match := range matches {
+		files[strings.TrimSuffix(strings.TrimPrefix(match, prefix), suffix)] = match
+	}
+	return files
+}
@@ -0,0 +1,105 @@
+package commands
+
+import (
+	"errors"
+	"github.com/goal-web/application"
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/goal/config"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// pidOnlyConfig 只提供 pid 文件配置
+type pidOnlyConfig struct {
+	contracts.Config
+	pid config.PidConfig
+}
+
+func (conf pidOnlyConfig) Get(string) any {
+	return conf.pid
+}
+
+func TestPidFile(t *testing.T) {
+	var path = filepath.Join(t.TempDir(), "pids", "queue-work.pid")
+
+	if _, err := runningPid(path); !errors.Is(err, NotRunningErr) {
+		t.Errorf("runningPid without pid file err = %v, want %v", err, NotRunningErr)
+	}
+
+	pid, err := lockPid(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if running, err := runningPid(path); err != nil || running != os.Getpid() {
+		t.Errorf("runningPid = %d, %v, want %d", running, err, os.Getpid())
+	}
+
+	// flock 作用于打开的文件，同一个进程再次加锁也会失败
+	_, err = lockPid(path)
+	if !errors.Is(err, AlreadyRunningErr) || !strings.Contains(err.Error(), strconv.Itoa(os.Getpid())) {
+		t.Errorf("second lockPid err = %v, want %v with the running pid", err, AlreadyRunningErr)
+	}
+
+	pid.release()
+	if _, err = os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("pid file left after release: %v", err)
+	}
+	if _, err = runningPid(path); !errors.Is(err, NotRunningErr) {
+		t.Errorf("runningPid after release err = %v, want %v", err, NotRunningErr)
+	}
+
+	// 异常退出留下的文件没有被锁住
+	if err = os.WriteFile(path, []byte("12345\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = runningPid(path); !errors.Is(err, NotRunningErr) {
+		t.Errorf("runningPid with a stale pid file err = %v, want %v", err, NotRunningErr)
+	}
+	pid, err = lockPid(path)
+	if err != nil {
+		t.Fatalf("lockPid over a stale pid file err = %v", err)
+	}
+	defer pid.release()
+	if content, _ := os.ReadFile(path); string(content) != strconv.Itoa(os.Getpid()) {
+		t.Errorf("pid file = %q, want the current pid only", content)
+	}
+}
+
+func TestPidFiles(t *testing.T) {
+	var (
+		dir = t.TempDir()
+		app = application.New()
+	)
+	app.Singleton("config", func() contracts.Config {
+		return pidOnlyConfig{pid: config.PidConfig{Path: filepath.Join(dir, "{role}.pid")}}
+	})
+	for _, name := range []string{"run.pid", "queue-work.pid", "other.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cases := []struct {
+		name     string
+		role     string
+		expected map[string]string
+	}{
+		{"given role", "queue:work", map[string]string{"queue:work": filepath.Join(dir, "queue-work.pid")}},
+		{"given role without pid file", "schedule:work", map[string]string{"schedule:work": filepath.Join(dir, "schedule-work.pid")}},
+		{"all existing", "", map[string]string{
+			"run":        filepath.Join(dir, "run.pid"),
+			"queue-work": filepath.Join(dir, "queue-work.pid"),
+		}},
+	}
+	for _, item := range cases {
+		t.Run(item.name, func(t *testing.T) {
+			if files := pidFiles(app, item.role); !reflect.DeepEqual(files, item.expected) {
+				t.Errorf("pidFiles = %v, want %v", files, item.expected)
+			}
+		})
+	}
+}
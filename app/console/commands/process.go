@@ -0,0 +1,107 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/goal/config"
+	"github.com/goal-web/supports/commands"
+	"os"
+	"sort"
+	"syscall"
+	"time"
+)
+
+// NewStop 通过 pid 文件向运行中的实例发送 SIGTERM，并等待其优雅关闭
+func NewStop(app contracts.Application) contracts.Command {
+	return &process{
+		Command: commands.Base("stop {role?}", "停止运行中的 goal，role 例如 serve、queue:work，为空时停止所有角色"),
+		app:     app,
+		signal:  syscall.SIGTERM,
+	}
+}
+
+// NewReload 通过 pid 文件向运行中的实例发送 SIGHUP
+func NewReload(app contracts.Application) contracts.Command {
+	return &process{
+		Command: commands.Base("reload {role?}", "通知运行中的 goal 重新加载配置，为空时通知所有角色"),
+		app:     app,
+		signal:  syscall.SIGHUP,
+	}
+}
+
+// NewStatus 查看各角色的运行状态
+func NewStatus(app contracts.Application) contracts.Command {
+	return &process{
+		Command: commands.Base("status {role?}", "查看 goal 的运行状态，为空时查看所有角色"),
+		app:     app,
+	}
+}
+
+type process struct {
+	commands.Command
+	app    contracts.Application
+	signal syscall.Signal
+}
+
+func (cmd *process) Handle() any {
+	var (
+		files  = pidFiles(cmd.app, cmd.GetString("role"))
+		roles  = make([]string, 0, len(files))
+		failed bool
+	)
+	for role := range files {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+
+	if len(roles) == 0 {
+		fmt.Println(NotRunningErr.Error())
+		os.Exit(1)
+	}
+
+	for _, role := range roles {
+		pid, err := runningPid(files[role])
+		switch {
+		case err != nil:
+			fmt.Printf("%s：%s\n", role, err.Error())
+			failed = failed || cmd.signal != 0 || !errors.Is(err, NotRunningErr)
+		case cmd.signal == 0:
+			fmt.Printf("%s：运行中，pid %d\n", role, pid)
+		default:
+			if err = syscall.Kill(pid, cmd.signal); err != nil {
+				fmt.Printf("%s：向 pid %d 发送 %s 失败：%s\n", role, pid, cmd.signal, err.Error())
+				failed = true
+			} else if cmd.signal == syscall.SIGTERM {
+				failed = !cmd.wait(role, files[role], pid) || failed
+			} else {
+				fmt.Printf("%s：已向 pid %d 发送 %s\n", role, pid, cmd.signal)
+			}
+		}
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+	return nil
+}
+
+// wait 等待进程释放 pid 文件，最多等待优雅关闭时间再加 5 秒
+func (cmd *process) wait(role, path string, pid int) bool {
+	var timeout = 30 * time.Second
+	cmd.app.Call(func(conf contracts.Config) {
+		if shutdownConfig, ok := conf.Get("shutdown").(config.ShutdownConfig); ok && shutdownConfig.Timeout > 0 {
+			timeout = shutdownConfig.Timeout
+		}
+	})
+
+	for deadline := time.Now().Add(timeout + 5*time.Second); time.Now().Before(deadline); time.Sleep(200 * time.Millisecond) {
+		if _, err := runningPid(path); errors.Is(err, NotRunningErr) {
+			fmt.Printf("%s：pid %d 已停止\n", role, pid)
+			return true
+		}
+	}
+
+	fmt.Printf("%s：pid %d 在 %s 内没有停止\n", role, pid, timeout+5*time.Second)
+	return false
+}
@@ -0,0 +1,128 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/goal/app/queue"
+	"github.com/goal-web/supports/commands"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+// NewQueueFailed 列出死信表中的任务
+func NewQueueFailed(app contracts.Application) contracts.Command {
+	return &QueueFailed{
+		Command: commands.Base("queue:failed {--queue=} {--json}", "列出执行失败的任务，--queue 只看指定队列，--json 输出 json"),
+		app:     app,
+	}
+}
+
+type QueueFailed struct {
+	commands.Command
+	app contracts.Application
+}
+
+func (cmd QueueFailed) Handle() any {
+	jobs, err := failedJobs(cmd.app).All(cmd.GetString("queue"))
+	exitOnError(err)
+
+	if cmd.GetBool("json") {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		_ = encoder.Encode(jobs)
+		return nil
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(writer, "ID\tCONNECTION\tQUEUE\tCLASS\tFAILED AT\tEXCEPTION")
+	for _, job := range jobs {
+		_, _ = fmt.Fprintf(writer, "%d\t%s\t%s\t%s\t%s\t%s\n", job.ID, job.Connection, job.Queue, job.Class,
+			job.FailedAt.Local().Format("2006-01-02 15:04:05"), job.Excerpt(80))
+	}
+	_ = writer.Flush()
+	fmt.Printf("共 %d 个失败的任务\n", len(jobs))
+	return nil
+}
+
+// NewQueueRetry 把失败的任务推回原来的连接和队列
+func NewQueueRetry(app contracts.Application) contracts.Command {
+	return &QueueRetry{
+		Command: commands.Base("queue:retry {ids}", "重试失败的任务，ids 为 all、单个 id、逗号分隔的 id 或者 id 范围，例如 1,2,5-8"),
+		app:     app,
+	}
+}
+
+type QueueRetry struct {
+	commands.Command
+	app contracts.Application
+}
+
+func (cmd QueueRetry) Handle() any {
+	var failed = failedJobs(cmd.app)
+	jobs, err := failed.Lookup(cmd.GetString("ids"))
+	exitOnError(err)
+
+	retried, err := failed.Retry(jobs...)
+	for _, id := range retried {
+		fmt.Printf("已重试：%d\n", id)
+	}
+	exitOnError(err)
+	if len(retried) == 0 {
+		fmt.Println("没有需要重试的任务")
+	}
+	return nil
+}
+
+// NewQueueForget 删除死信表中的任务
+func NewQueueForget(app contracts.Application) contracts.Command {
+	return &QueueForget{
+		Command: commands.Base("queue:forget {ids}", "删除失败的任务，ids 为单个 id、逗号分隔的 id 或者 id 范围"),
+		app:     app,
+	}
+}
+
+type QueueForget struct {
+	commands.Command
+	app contracts.Application
+}
+
+func (cmd QueueForget) Handle() any {
+	ids, err := queue.ParseIDs(cmd.GetString("ids"))
+	exitOnError(err)
+	deleted, err := failedJobs(cmd.app).Forget(ids...)
+	exitOnError(err)
+	fmt.Printf("已删除 %d 个失败的任务\n", deleted)
+	return nil
+}
+
+// NewQueueFlush 清理死信表
+func NewQueueFlush(app contracts.Application) contracts.Command {
+	return &QueueFlush{
+		Command: commands.Base("queue:flush {--days=0} {--force}", "删除失败时间早于 --days 天的任务，为 0 时删除所有失败的任务"),
+		app:     app,
+	}
+}
+
+type QueueFlush struct {
+	commands.Command
+	app contracts.Application
+}
+
+func (cmd QueueFlush) Handle() any {
+	var before time.Time
+	if days := cmd.GetInt("days"); days > 0 {
+		before = time.Now().AddDate(0, 0, -days)
+	} else {
+		confirmProduction(cmd.app, cmd.GetBool("force"))
+	}
+	deleted, err := failedJobs(cmd.app).Flush(before)
+	exitOnError(err)
+	fmt.Printf("已删除 %d 个失败的任务\n", deleted)
+	return nil
+}
+
+func failedJobs(app contracts.Application) *queue.FailedJobs {
+	return app.Get("queue.failed").(*queue.FailedJobs)
+}
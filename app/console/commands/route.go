@@ -0,0 +1,315 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/goal/routes"
+	"github.com/goal-web/supports/commands"
+	"os"
+	"reflect"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// NewRouteList 列出 routes.Collectors 中注册的所有路由，并提示重复、冲突的路由
+func NewRouteList(app contracts.Application) contracts.Command {
+	return &RouteList{
+		Command: commands.Base("route:list {filter?} {--method=} {--type=} {--json}",
+			"列出所有路由，filter 匹配路径或者处理函数，--type 为 http、ws 或者 sse，--json 输出 json"),
+	}
+}
+
+type RouteList struct {
+	commands.Command
+}
+
+// RouteInfo 一条路由
+type RouteInfo struct {
+	Method      string   `json:"method"`
+	Path        string   `json:"path"`
+	Handler     string   `json:"handler"`
+	Middlewares []string `json:"middlewares"`
+	Type        string   `json:"type"`
+}
+
+func (cmd RouteList) Handle() any {
+	var (
+		all      = CollectRoutes()
+		warnings = RouteWarnings(all)
+		filter   = strings.ToLower(cmd.GetString("filter"))
+		method   = strings.ToUpper(cmd.GetString("method"))
+		kind     = strings.ToLower(cmd.GetString("type"))
+		matched  = make([]RouteInfo, 0)
+	)
+	for _, route := range all {
+		if filter != "" && !strings.Contains(strings.ToLower(route.Path), filter) && !strings.Contains(strings.ToLower(route.Handler), filter) {
+			continue
+		}
+		if (method != "" && route.Method != method) || (kind != "" && route.Type != kind) {
+			continue
+		}
+		matched = append(matched, route)
+	}
+
+	if cmd.GetBool("json") {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		_ = encoder.Encode(map[string]any{"routes": matched, "warnings": warnings})
+		return nil
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(writer, "TYPE\tMETHOD\tPATH\tHANDLER\tMIDDLEWARES")
+	for _, route := range matched {
+		_, _ = fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\n", route.Type, route.Method, route.Path, route.Handler, strings.Join(route.Middlewares, ", "))
+	}
+	_ = writer.Flush()
+	fmt.Printf("共 %d 条路由\n", len(matched))
+
+	for _, warning := range warnings {
+		fmt.Println("警告：" + warning)
+	}
+	return nil
+}
+
+// CollectRoutes 用记录路由的 Router 执行各个路由收集函数，不会启动 http 服务
+func CollectRoutes() []RouteInfo {
+	var results = make([]RouteInfo, 0)
+	for _, collector := range routes.Collectors {
+		router := &routeRecorder{routeGroupRecorder: &routeGroupRecorder{kind: collector.Type}}
+		collector.Collect(router)
+		results = append(results, router.routes()...)
+	}
+	return results
+}
+
+// paramPattern 路径参数，:id 和 :name 在路由匹配时是等价的
+var paramPattern = regexp.MustCompile(`:[^/]+`)
+
+// RouteWarnings 同一方法下路径完全相同的为重复路由，只有参数名不同的为冲突路由，只有先注册的会生效
+func RouteWarnings(items []RouteInfo) []string {
+	var (
+		warnings = make([]string, 0)
+		exact    = make(map[string][]RouteInfo)
+		shapes   = make(map[string][]RouteInfo)
+		keys     = make([]string, 0)
+	)
+	for _, route := range items {
+		key := route.Method + " " + route.Path
+		if _, exists := exact[key]; !exists {
+			keys = append(keys, key)
+		}
+		exact[key] = append(exact[key], route)
+		shape := route.Method + " " + paramPattern.ReplaceAllString(route.Path, ":")
+		shapes[shape] = append(shapes[shape], route)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if duplicates := exact[key]; len(duplicates) > 1 {
+			warnings = append(warnings, fmt.Sprintf("%s 重复注册了 %d 次：%s", key, len(duplicates), describeRoutes(duplicates)))
+		}
+	}
+
+	var conflicts = make([]string, 0)
+	for _, routes := range shapes {
+		var paths = make(map[string]bool)
+		for _, route := range routes {
+			paths[route.Path] = true
+		}
+		if len(paths) > 1 {
+			conflicts = append(conflicts, fmt.Sprintf("%s %s 只有参数名不同，互相冲突：%s", routes[0].Method, routes[0].Path, describeRoutes(routes)))
+		}
+	}
+	sort.Strings(conflicts)
+
+	return append(warnings, conflicts...)
+}
+
+func describeRoutes(items []RouteInfo) string {
+	var descriptions = make([]string, 0, len(items))
+	for _, route := range items {
+		description := fmt.Sprintf("%s %s（%s）", route.Path, route.Handler, route.Type)
+		if len(route.Middlewares) > 0 {
+			description += " [" + strings.Join(route.Middlewares, ", ") + "]"
+		}
+		descriptions = append(descriptions, description)
+	}
+	return strings.Join(descriptions, "；")
+}
+
+// funcName 函数的包名加函数名，例如 controllers.HelloWorld，匿名函数为 routes.Sse.func1
+func funcName(value any) string {
+	if value == nil {
+		return ""
+	}
+	reflected := reflect.ValueOf(value)
+	if reflected.Kind() != reflect.Func {
+		return reflect.TypeOf(value).String()
+	}
+	name := runtime.FuncForPC(reflected.Pointer()).Name()
+	name = name[strings.LastIndex(name, "/")+1:]
+	return strings.TrimSuffix(name, "-fm")
+}
+
+func funcNames(values []any) []string {
+	var names = make([]string, 0, len(values))
+	for _, value := range values {
+		names = append(names, funcName(value))
+	}
+	return names
+}
+
+// routeRecorder 只记录路由的 contracts.Router
+type routeRecorder struct {
+	*routeGroupRecorder
+	global []any
+}
+
+func (router *routeRecorder) Static(path string, directory string) {
+	router.routeGroupRecorder.Get(path+"*", "static:"+directory)
+}
+
+func (router *routeRecorder) Get(path string, handler any, middlewares ...any) {
+	router.routeGroupRecorder.Get(path, handler, middlewares...)
+}
+
+func (router *routeRecorder) Post(path string, handler any, middlewares ...any) {
+	router.routeGroupRecorder.Post(path, handler, middlewares...)
+}
+
+func (router *routeRecorder) Delete(path string, handler any, middlewares ...any) {
+	router.routeGroupRecorder.Delete(path, handler, middlewares...)
+}
+
+func (router *routeRecorder) Put(path string, handler any, middlewares ...any) {
+	router.routeGroupRecorder.Put(path, handler, middlewares...)
+}
+
+func (router *routeRecorder) Patch(path string, handler any, middlewares ...any) {
+	router.routeGroupRecorder.Patch(path, handler, middlewares...)
+}
+
+func (router *routeRecorder) Options(path string, handler any, middlewares ...any) {
+	router.routeGroupRecorder.Options(path, handler, middlewares...)
+}
+
+func (router *routeRecorder) Trace(path string, handler any, middlewares ...any) {
+	router.routeGroupRecorder.Trace(path, handler, middlewares...)
+}
+
+// Use 全局中间件作用于所有路由，包括之前注册的
+func (router *routeRecorder) Use(middlewares ...any) {
+	router.global = append(router.global, middlewares...)
+}
+
+func (router *routeRecorder) Start(address string) error {
+	return nil
+}
+
+func (router *routeRecorder) Close() error {
+	return nil
+}
+
+func (router *routeRecorder) routes() []RouteInfo {
+	var results = router.routeGroupRecorder.collect(funcNames(router.global))
+	for i := range results {
+		results[i].Type = router.kind
+	}
+	return results
+}
+
+type recordedRoute struct {
+	method      string
+	path        string
+	handler     any
+	middlewares []any
+}
+
+// routeGroupRecorder 只记录路由的 contracts.RouteGroup
+type routeGroupRecorder struct {
+	kind        string
+	prefix      string
+	middlewares []any
+	recorded    []recordedRoute
+	groups      []*routeGroupRecorder
+}
+
+func (group *routeGroupRecorder) add(method, path string, handler any, middlewares ...any) contracts.RouteGroup {
+	group.recorded = append(group.recorded, recordedRoute{method: method, path: group.prefix + path, handler: handler, middlewares: middlewares})
+	return group
+}
+
+func (group *routeGroupRecorder) Get(path string, handler any, middlewares ...any) contracts.RouteGroup {
+	return group.add("GET", path, handler, middlewares...)
+}
+
+func (group *routeGroupRecorder) Post(path string, handler any, middlewares ...any) contracts.RouteGroup {
+	return group.add("POST", path, handler, middlewares...)
+}
+
+func (group *routeGroupRecorder) Delete(path string, handler any, middlewares ...any) contracts.RouteGroup {
+	return group.add("DELETE", path, handler, middlewares...)
+}
+
+func (group *routeGroupRecorder) Put(path string, handler any, middlewares ...any) contracts.RouteGroup {
+	return group.add("PUT", path, handler, middlewares...)
+}
+
+func (group *routeGroupRecorder) Patch(path string, handler any, middlewares ...any) contracts.RouteGroup {
+	return group.add("PATCH", path, handler, middlewares...)
+}
+
+func (group *routeGroupRecorder) Options(path string, handler any, middlewares ...any) contracts.RouteGroup {
+	return group.add("OPTIONS", path, handler, middlewares...)
+}
+
+func (group *routeGroupRecorder) Trace(path string, handler any, middlewares ...any) contracts.RouteGroup {
+	return group.add("TRACE", path, handler, middlewares...)
+}
+
+func (group *routeGroupRecorder) Middlewares() []contracts.MagicalFunc {
+	return nil
+}
+
+func (group *routeGroupRecorder) Group(prefix string, middlewares ...any) contracts.RouteGroup {
+	child := &routeGroupRecorder{kind: group.kind, prefix: group.prefix + prefix, middlewares: middlewares}
+	group.groups = append(group.groups, child)
+	return child
+}
+
+func (group *routeGroupRecorder) Routes() []contracts.Route {
+	return nil
+}
+
+func (group *routeGroupRecorder) Groups() []contracts.RouteGroup {
+	return nil
+}
+
+// collect 中间件按执行顺序排列：全局、各级分组、路由自己的
+func (group *routeGroupRecorder) collect(parents []string) []RouteInfo {
+	var (
+		results     = make([]RouteInfo, 0)
+		middlewares = append(append([]string{}, parents...), funcNames(group.middlewares)...)
+	)
+	for _, route := range group.recorded {
+		handler, isStatic := route.handler.(string)
+		if !isStatic {
+			handler = funcName(route.handler)
+		}
+		results = append(results, RouteInfo{
+			Method:      route.method,
+			Path:        route.path,
+			Handler:     handler,
+			Middlewares: append(append([]string{}, middlewares...), funcNames(route.middlewares)...),
+		})
+	}
+	for _, child := range group.groups {
+		results = append(results, child.collect(middlewares)...)
+	}
+	return results
+}
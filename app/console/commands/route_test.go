@@ -0,0 +1,121 @@
+package commands
+
+import (
+	"github.com/goal-web/contracts"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func index() {}
+
+func show() {}
+
+func auth() {}
+
+func logging() {}
+
+func throttle() {}
+
+func TestRouteRecorder(t *testing.T) {
+	var router = &routeRecorder{routeGroupRecorder: &routeGroupRecorder{kind: "http"}}
+	router.Get("/", index, throttle)
+	users := router.Group("/users", auth)
+	users.Get("", index)
+	users.Group("/:id").Put("", show, throttle)
+	router.Static("/assets", "public")
+	router.Use(logging) // 全局中间件作用于之前注册的路由
+
+	expected := []RouteInfo{
+		{Method: "GET", Path: "/", Handler: "commands.index", Middlewares: []string{"commands.logging", "commands.throttle"}, Type: "http"},
+		{Method: "GET", Path: "/assets*", Handler: "static:public", Middlewares: []string{"commands.logging"}, Type: "http"},
+		{Method: "GET", Path: "/users", Handler: "commands.index", Middlewares: []string{"commands.logging", "commands.auth"}, Type: "http"},
+		{Method: "PUT", Path: "/users/:id", Handler: "commands.show", Middlewares: []string{"commands.logging", "commands.auth", "commands.throttle"}, Type: "http"},
+	}
+	if routes := router.routes(); !reflect.DeepEqual(routes, expected) {
+		t.Errorf("routes = %+v\nwant %+v", routes, expected)
+	}
+}
+
+func TestFuncName(t *testing.T) {
+	var closure = func() {}
+	cases := []struct {
+		name     string
+		value    any
+		expected string
+	}{
+		{"nil", nil, ""},
+		{"function", index, "commands.index"},
+		{"method value", RouteList{}.Handle, "commands.RouteList.Handle"},
+		{"closure", closure, "commands.TestFuncName.func1"},
+		{"not a function", contracts.Fields{}, "contracts.Fields"},
+	}
+	for _, item := range cases {
+		t.Run(item.name, func(t *testing.T) {
+			if name := funcName(item.value); name != item.expected {
+				t.Errorf("funcName = %q, want %q", name, item.expected)
+			}
+		})
+	}
+}
+
+func TestRouteWarnings(t *testing.T) {
+	route := func(method, path, handler string) RouteInfo {
+		return RouteInfo{Method: method, Path: path, Handler: handler, Type: "http"}
+	}
+	cases := []struct {
+		name     string
+		routes   []RouteInfo
+		expected []string
+	}{
+		{
+			name:     "no warnings",
+			routes:   []RouteInfo{route("GET", "/users", "a"), route("POST", "/users", "b"), route("GET", "/users/:id", "c")},
+			expected: []string{},
+		},
+		{
+			name:     "duplicate",
+			routes:   []RouteInfo{route("GET", "/users", "a"), route("GET", "/users", "b"), route("GET", "/users", "c")},
+			expected: []string{"GET /users 重复注册了 3 次", "/users a（http）；/users b（http）；/users c（http）"},
+		},
+		{
+			name:     "conflict",
+			routes:   []RouteInfo{route("GET", "/users/:id", "a"), route("GET", "/users/:name", "b")},
+			expected: []string{"GET /users/:id 只有参数名不同，互相冲突", "/users/:id a（http）；/users/:name b（http）"},
+		},
+		{
+			name:     "other methods do not conflict",
+			routes:   []RouteInfo{route("GET", "/users/:id", "a"), route("PUT", "/users/:name", "b")},
+			expected: []string{},
+		},
+	}
+	for _, item := range cases {
+		t.Run(item.name, func(t *testing.T) {
+			warnings := RouteWarnings(item.routes)
+			if len(item.expected) == 0 {
+				if len(warnings) > 0 {
+					t.Errorf("warnings = %v, want none", warnings)
+				}
+				return
+			}
+			if len(warnings) != 1 {
+				t.Fatalf("warnings = %v, want one", warnings)
+			}
+			for _, expected := range item.expected {
+				if !strings.Contains(warnings[0], expected) {
+					t.Errorf("warning = %q, want it to contain %q", warnings[0], expected)
+				}
+			}
+		})
+	}
+
+	t.Run("duplicates before conflicts", func(t *testing.T) {
+		warnings := RouteWarnings([]RouteInfo{
+			route("GET", "/posts/:id", "a"), route("GET", "/posts/:slug", "b"),
+			route("GET", "/users", "c"), route("GET", "/users", "d"),
+		})
+		if len(warnings) != 2 || !strings.Contains(warnings[0], "重复") || !strings.Contains(warnings[1], "冲突") {
+			t.Errorf("warnings = %v", warnings)
+		}
+	})
+}
@@ -1,12 +1,13 @@
 package commands
 
 import (
-	"fmt"
 	"github.com/goal-web/contracts"
+	appqueue "github.com/goal-web/goal/app/queue"
+	"github.com/goal-web/queue"
 	"github.com/goal-web/supports/commands"
 	"github.com/goal-web/supports/logs"
-	"io/ioutil"
-	"os"
+	"strings"
+	"time"
 )
 
 type runner struct {
@@ -16,22 +17,113 @@ type runner struct {
 
 func Runner(app contracts.Application) contracts.Command {
 	return &runner{
-		Command: commands.Base("run", "启动 goal"),
+		Command: commands.Base("run", "启动 goal 的所有服务"),
+		app:     app,
+	}
+}
+
+// NewServe 启动 http、websocket、sse 服务
+func NewServe(app contracts.Application) contracts.Command {
+	return &runner{
+		Command: commands.Base("serve", "启动 http 服务"),
+		app:     app,
+	}
+}
+
+// NewQueueWork 启动队列消费者，没有指定 --connection、--queue 时按 config/queue.go 中当前环境的 Workers 启动
+func NewQueueWork(app contracts.Application) contracts.Command {
+	return &QueueWork{runner: runner{
+		Command: commands.Base(
+			"queue:work {--connection=} {--queue=} {--tries=0} {--timeout=0} {--sleep=3} {--max-jobs=0} {--max-time=0} {--once}",
+			"启动队列消费者，--queue 为逗号分隔、按优先级排列的队列，--timeout、--sleep、--max-time 单位为秒",
+		),
+		app: app,
+	}}
+}
+
+// NewScheduleWork 启动任务调度
+func NewScheduleWork(app contracts.Application) contracts.Command {
+	return &runner{
+		Command: commands.Base("schedule:work", "启动任务调度"),
+		app:     app,
+	}
+}
+
+// NewMicroServe 启动微服务服务端
+func NewMicroServe(app contracts.Application) contracts.Command {
+	return &runner{
+		Command: commands.Base("micro:serve", "启动微服务"),
 		app:     app,
 	}
 }
 
 func (runner *runner) Handle() any {
-	path, _ := os.Getwd()
-	pidPath := path + "/goal.pid"
-	// 写入 pid 文件
-	_ = ioutil.WriteFile(pidPath, []byte(fmt.Sprintf("%d", os.Getpid())), os.ModePerm)
+	return runner.run(runner.GetName())
+}
+
+func (runner *runner) run(role string) any {
+	// 写入并锁住 pid 文件，同一角色只能启动一个实例，stop、status、reload 通过它找到进程
+	pid, err := lockPid(pidConfig(runner.app).Role(role))
+	if err != nil {
+		logs.WithError(err).Fatal("goal 启动异常!")
+	}
+	defer pid.release()
 
 	if errors := runner.app.Start(); len(errors) > 0 {
+		pid.release()
 		logs.WithField("errors", errors).Fatal("goal 启动异常!")
 	} else {
-		_ = os.Remove(pidPath)
 		logs.Default().Info("goal 已关闭")
 	}
 	return nil
 }
+
+type QueueWork struct {
+	runner
+}
+
+// Handle 把选项转换为 worker 参数交给队列服务，指定了连接或者队列时只启动一个 worker
+// 此时 pid 文件按连接和队列区分，例如 queue:work:redis:high，可以同时为不同的队列启动多个进程
+func (cmd *QueueWork) Handle() any {
+	var (
+		config     = cmd.app.Get("config").(contracts.Config).Get("queue").(queue.Config)
+		connection = cmd.GetString("connection")
+		queues     = cmd.GetString("queue")
+		role       = cmd.GetName()
+		workers    []appqueue.WorkerOptions
+	)
+	if connection == "" && queues == "" {
+		workers = appqueue.ConfigWorkers(config, cmd.app.Get("config").(contracts.Config).GetString("app.env"))
+	} else {
+		if connection == "" {
+			connection = config.Defaults.Connection
+		}
+		if queues == "" {
+			queues = config.Defaults.Queue
+		}
+		workers = []appqueue.WorkerOptions{{
+			Name:       "cli",
+			Connection: connection,
+			Queues:     strings.Split(queues, ","),
+			Processes:  1,
+			Sleep:      appqueue.DefaultSleep,
+		}}
+		role = strings.Join([]string{role, connection, queues}, ":")
+	}
+
+	for i := range workers {
+		if tries := cmd.GetInt("tries"); tries > 0 {
+			workers[i].Tries = tries
+		}
+		if timeout := cmd.GetInt("timeout"); timeout > 0 {
+			workers[i].Timeout = time.Duration(timeout) * time.Second
+		}
+		workers[i].Sleep = time.Duration(cmd.GetFloat64("sleep") * float64(time.Second))
+		workers[i].MaxJobs = cmd.GetInt("max-jobs")
+		workers[i].MaxTime = time.Duration(cmd.GetInt("max-time")) * time.Second
+		workers[i].Once = cmd.GetBool("once")
+	}
+	cmd.app.Instance("queue.workers", workers)
+
+	return cmd.run(role)
+}
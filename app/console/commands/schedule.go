@@ -0,0 +1,162 @@
+package commands
+
+import (
+	"fmt"
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/goal/app/scheduling"
+	"github.com/goal-web/supports/commands"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// NewScheduleList 列出 Kernel.Schedule 中的所有任务以及下一次执行时间
+func NewScheduleList(app contracts.Application) contracts.Command {
+	return &ScheduleList{
+		Command: commands.Base("schedule:list", "列出所有调度任务的 cron 表达式、时区和下一次执行时间"),
+		app:     app,
+	}
+}
+
+type ScheduleList struct {
+	commands.Command
+	app contracts.Application
+}
+
+func (cmd ScheduleList) Handle() any {
+	var (
+		now    = time.Now()
+		writer = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	)
+	_, _ = fmt.Fprintln(writer, "#\tNAME\tEXPRESSION\tTIMEZONE\tNEXT RUN")
+	for _, task := range scheduleTasks(cmd.app) {
+		var next, err = task.Next(now)
+		var nextRun = next.Format("2006-01-02 15:04:05 -07:00")
+		if err != nil {
+			nextRun = err.Error()
+		}
+		timezone := task.Timezone()
+		if timezone == "" {
+			timezone = task.Location().String()
+		}
+		_, _ = fmt.Fprintf(writer, "%d\t%s\t%s\t%s\t%s\n", task.Index, task.Name(), task.Expression(), timezone, nextRun)
+	}
+	_ = writer.Flush()
+	return nil
+}
+
+// NewScheduleRun 执行当前这一分钟内到期的任务，供系统 cron 每分钟调用一次，代替常驻的 schedule:work
+func NewScheduleRun(app contracts.Application) contracts.Command {
+	return &ScheduleRun{
+		Command: commands.Base("schedule:run", "执行当前这一分钟内到期的调度任务，每个任务执行一次，适合由系统 cron 每分钟调用"),
+		app:     app,
+	}
+}
+
+type ScheduleRun struct {
+	commands.Command
+	app contracts.Application
+}
+
+func (cmd ScheduleRun) Handle() any {
+	var (
+		now = time.Now()
+		due = make([]scheduling.Task, 0)
+	)
+	for _, task := range scheduleTasks(cmd.app) {
+		if task.DueWithin(now) {
+			due = append(due, task)
+		}
+	}
+	if len(due) == 0 {
+		fmt.Println("没有到期的调度任务")
+		return nil
+	}
+
+	// 并发执行前解析一次执行历史的连接
+	_, _ = cmd.app.Get("scheduling").(*scheduling.Schedule).History()
+
+	var failed = false
+	for _, result := range scheduling.RunAll(cmd.app, due, now.Truncate(time.Minute)) {
+		printScheduleResult(result)
+		failed = failed || (result.Err != nil && !result.Skipped())
+	}
+	if failed {
+		os.Exit(1)
+	}
+	return nil
+}
+
+// NewScheduleTest 立即执行一个调度任务，不检查 cron 表达式
+func NewScheduleTest(app contracts.Application) contracts.Command {
+	return &ScheduleTest{
+		Command: commands.Base("schedule:test {task}", "立即执行一个调度任务，task 为 schedule:list 中的名称或者序号"),
+		app:     app,
+	}
+}
+
+type ScheduleTest struct {
+	commands.Command
+	app contracts.Application
+}
+
+func (cmd ScheduleTest) Handle() any {
+	task, err := scheduling.Find(scheduleTasks(cmd.app), cmd.GetString("task"))
+	exitOnError(err)
+
+	result := task.Run(cmd.app, time.Time{})
+	printScheduleResult(result)
+	if result.Err != nil && !result.Skipped() {
+		os.Exit(1)
+	}
+	return nil
+}
+
+func scheduleTasks(app contracts.Application) []scheduling.Task {
+	return scheduling.Tasks(app.Get("scheduling").(*scheduling.Schedule))
+}
+
+func printScheduleResult(result scheduling.Result) {
+	var outcome = "完成"
+	switch {
+	case result.Skipped():
+		outcome = "跳过：" + result.Err.Error()
+	case result.Err != nil:
+		outcome = "失败：" + result.Err.Error()
+	}
+	fmt.Printf("[%s] #%d %s %s（%s）\n", result.StartedAt.Format("2006-01-02 15:04:05"), result.Task.Index, result.Task.Name(), outcome, result.Duration.Round(time.Millisecond))
+}
+
+// NewScheduleHistory 查看调度任务的执行历史
+func NewScheduleHistory(app contracts.Application) contracts.Command {
+	return &ScheduleHistory{
+		Command: commands.Base("schedule:history {task?} {--limit=20} {--failed}", "查看调度任务的执行历史，task 为任务名称，--failed 只看失败的执行"),
+		app:     app,
+	}
+}
+
+type ScheduleHistory struct {
+	commands.Command
+	app contracts.Application
+}
+
+func (cmd ScheduleHistory) Handle() any {
+	var outcome string
+	if cmd.GetBool("failed") {
+		outcome = scheduling.OutcomeFailed
+	}
+	history, err := cmd.app.Get("scheduling").(*scheduling.Schedule).History()
+	exitOnError(err)
+	runs, err := history.Recent(cmd.GetString("task"), outcome, cmd.GetInt("limit"))
+	exitOnError(err)
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(writer, "ID\tTASK\tSTARTED AT\tDURATION\tOUTCOME\tMESSAGE")
+	for _, run := range runs {
+		_, _ = fmt.Fprintf(writer, "%d\t%s\t%s\t%s\t%s\t%s\n", run.ID, run.Task, run.StartedAt.Local().Format("2006-01-02 15:04:05"),
+			run.Duration, run.Outcome, strings.ReplaceAll(run.Message, "\n", " "))
+	}
+	_ = writer.Flush()
+	return nil
+}
@@ -0,0 +1,43 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/goal/database/seeders"
+	"github.com/goal-web/supports/commands"
+	"strings"
+)
+
+var SeederNotFoundErr = errors.New("填充类不存在")
+
+// NewDbSeed 执行 database/seeders 中的填充类
+func NewDbSeed(app contracts.Application) contracts.Command {
+	return &DbSeed{
+		Command: commands.Base("db:seed {--class=DatabaseSeeder} {--connection=} {--force}", "填充数据，--class 为填充类，--connection 为 config/database.go 中的连接"),
+		app:     app,
+	}
+}
+
+type DbSeed struct {
+	commands.Command
+	app contracts.Application
+}
+
+func (cmd DbSeed) Handle() any {
+	confirmProduction(cmd.app, cmd.GetBool("force"))
+	exitOnError(seed(cmd.GetString("class"), cmd.GetString("connection")))
+	return nil
+}
+
+func seed(class, connection string) error {
+	seeder, exists := seeders.Find(class)
+	if !exists {
+		return fmt.Errorf("%w：%s，可选：%s", SeederNotFoundErr, class, strings.Join(seeders.Names(), "、"))
+	}
+	if err := seeder.Run(connection); err != nil {
+		return err
+	}
+	fmt.Println("已填充：" + class)
+	return nil
+}
@@ -0,0 +1,17 @@
+package commands
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSeedMissingClass(t *testing.T) {
+	err := seed("Missing", "")
+	if !errors.Is(err, SeederNotFoundErr) {
+		t.Fatalf("err = %v, want %v", err, SeederNotFoundErr)
+	}
+	if !strings.Contains(err.Error(), "ArticleSeeder、DatabaseSeeder、UserSeeder") {
+		t.Errorf("err = %v, want it to list the seeders", err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/goal-web/console"
 	"github.com/goal-web/contracts"
 	"github.com/goal-web/goal/app/console/commands"
+	"github.com/goal-web/goal/app/scheduling"
 	"github.com/goal-web/supports/logs"
 )
 
@@ -12,19 +13,59 @@ func NewService() contracts.ServiceProvider {
 }
 
 func NewKernel(app contracts.Application) contracts.Console {
-	return &Kernel{console.NewKernel(app, []contracts.CommandProvider{
+	return &Kernel{Kernel: console.NewKernel(app, []contracts.CommandProvider{
 		commands.Runner,
+		commands.NewServe,
+		commands.NewQueueWork,
+		commands.NewScheduleWork,
+		commands.NewMicroServe,
+		commands.NewStop,
+		commands.NewStatus,
+		commands.NewReload,
+		commands.NewConfigCheck,
+		commands.NewConfigShow,
+		commands.NewConfigEncrypt,
+		commands.NewConfigDecrypt,
+		commands.NewKeyGenerate,
+		commands.NewMakeController,
+		commands.NewMakeJob,
+		commands.NewMakeListener,
+		commands.NewMakeCommand,
+		commands.NewMakePolicy,
+		commands.NewMakeRequest,
+		commands.NewMakeMiddleware,
+		commands.NewMakeModel,
+		commands.NewRouteList,
+		commands.NewMigrate,
+		commands.NewMigrateRollback,
+		commands.NewMigrateStatus,
+		commands.NewMigrateFresh,
+		commands.NewDbSeed,
+		commands.NewScheduleList,
+		commands.NewScheduleRun,
+		commands.NewScheduleTest,
+		commands.NewScheduleHistory,
+		commands.NewQueueFailed,
+		commands.NewQueueRetry,
+		commands.NewQueueForget,
+		commands.NewQueueFlush,
 		commands.NewHello,
-	}), app}
+	}), app: app, schedule: scheduling.NewSchedule(app)}
 }
 
 type Kernel struct {
 	*console.Kernel
-	app contracts.Application
+	app      contracts.Application
+	schedule *scheduling.Schedule
+}
+
+// GetSchedule 使用 app/scheduling 的 Schedule，支持按时区调度以及基于锁的 WithoutOverlapping、OnOneServer
+func (kernel *Kernel) GetSchedule() contracts.Schedule {
+	return kernel.schedule
 }
 
 func (kernel *Kernel) Schedule(schedule contracts.Schedule) {
 	schedule.Call(func() {
 		logs.Default().Info("周日每5秒钟打印 周日愉快")
-	}).EveryFiveSeconds().Sundays()
+	}).Description("周日愉快").EveryFiveSeconds().Sundays()
 }
@@ -0,0 +1,92 @@
+package dbcheck
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/supports/utils"
+	"os"
+	"strings"
+)
+
+var UnsupportedDriverErr = errors.New("不支持的数据库驱动")
+
+// Ping 按连接配置直接建立连接并 ping，不经过 db.factory
+// 框架的数据库驱动连接失败时会直接退出进程，健康检查等不能让进程退出的地方先用它确认连接可用
+// 驱动名和 dsn 与框架的数据库驱动保持一致，驱动由框架的数据库包注册
+func Ping(ctx context.Context, fields contracts.Fields) error {
+	driver, dsn, err := DSN(fields)
+	if err != nil {
+		return err
+	}
+	if driver == "sqlite3" {
+		// sqlite 打开不存在的文件时会创建它
+		if _, err = os.Stat(dsn); err != nil {
+			return err
+		}
+	}
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return db.PingContext(ctx)
+}
+
+// DSN 连接配置对应的 database/sql 驱动名和 dsn
+func DSN(fields contracts.Fields) (string, string, error) {
+	switch driver := utils.GetStringField(fields, "driver"); driver {
+	case "mysql":
+		dsn := utils.GetStringField(fields, "unix_socket")
+		if dsn == "" {
+			dsn = fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=%s",
+				utils.GetStringField(fields, "username"),
+				utils.GetStringField(fields, "password"),
+				utils.GetStringField(fields, "host"),
+				utils.GetStringField(fields, "port"),
+				utils.GetStringField(fields, "database"),
+				utils.GetStringField(fields, "charset"),
+			)
+		}
+		return "mysql", dsn, nil
+	case "postgres":
+		return "postgres", fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+			utils.GetStringField(fields, "host"),
+			utils.GetStringField(fields, "port"),
+			utils.GetStringField(fields, "username"),
+			utils.GetStringField(fields, "password"),
+			utils.GetStringField(fields, "database"),
+			utils.GetStringField(fields, "sslmode"),
+		), nil
+	case "sqlite":
+		return "sqlite3", utils.GetStringField(fields, "database"), nil
+	case "clickhouse":
+		return "clickhouse", clickhouseDSN(fields), nil
+	default:
+		return "", "", fmt.Errorf("%w：%s", UnsupportedDriverErr, driver)
+	}
+}
+
+func clickhouseDSN(fields contracts.Fields) string {
+	if dsn := utils.GetStringField(fields, "dsn"); dsn != "" {
+		return dsn
+	}
+	var (
+		addresses, _ = fields["address"].([]string)
+		params       = []string{"debug=" + utils.GetStringField(fields, "debug")}
+	)
+	for _, key := range []string{"username", "database", "password"} {
+		if value := utils.GetStringField(fields, key); value != "" {
+			params = append(params, key+"="+value)
+		}
+	}
+	if len(addresses) == 0 {
+		addresses = []string{""}
+	}
+	if len(addresses) > 1 {
+		params = append(params, "alt_hosts="+strings.Join(addresses[1:], ","))
+	}
+	return fmt.Sprintf("tcp://%s?%s", addresses[0], strings.Join(params, "&"))
+}
@@ -0,0 +1,113 @@
+package dbcheck
+
+import (
+	"context"
+	"errors"
+	"github.com/goal-web/contracts"
+	_ "github.com/goal-web/database/drivers" // 注册 database/sql 驱动
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDSN(t *testing.T) {
+	cases := []struct {
+		name   string
+		fields contracts.Fields
+		driver string
+		dsn    string
+		err    error
+	}{
+		{
+			name:   "mysql",
+			fields: contracts.Fields{"driver": "mysql", "host": "db", "port": "3306", "database": "goal", "username": "root", "password": "secret", "charset": "utf8mb4"},
+			driver: "mysql",
+			dsn:    "root:secret@tcp(db:3306)/goal?charset=utf8mb4",
+		},
+		{
+			name:   "mysql unix socket",
+			fields: contracts.Fields{"driver": "mysql", "host": "db", "unix_socket": "root@unix(/tmp/mysql.sock)/goal"},
+			driver: "mysql",
+			dsn:    "root@unix(/tmp/mysql.sock)/goal",
+		},
+		{
+			name:   "postgres",
+			fields: contracts.Fields{"driver": "postgres", "host": "db", "port": "5432", "database": "goal", "username": "postgres", "password": "secret", "sslmode": "disable"},
+			driver: "postgres",
+			dsn:    "host=db port=5432 user=postgres password=secret dbname=goal sslmode=disable",
+		},
+		{
+			name:   "sqlite",
+			fields: contracts.Fields{"driver": "sqlite", "database": "/tmp/goal.db"},
+			driver: "sqlite3",
+			dsn:    "/tmp/goal.db",
+		},
+		{
+			name:   "clickhouse dsn",
+			fields: contracts.Fields{"driver": "clickhouse", "dsn": "tcp://ch:9000?debug=false", "address": []string{"other:9000"}},
+			driver: "clickhouse",
+			dsn:    "tcp://ch:9000?debug=false",
+		},
+		{
+			name:   "clickhouse addresses",
+			fields: contracts.Fields{"driver": "clickhouse", "address": []string{"a:9000", "b:9000", "c:9000"}, "username": "default", "debug": "false"},
+			driver: "clickhouse",
+			dsn:    "tcp://a:9000?debug=false&username=default&alt_hosts=b:9000,c:9000",
+		},
+		{
+			name:   "clickhouse without address",
+			fields: contracts.Fields{"driver": "clickhouse", "debug": "true"},
+			driver: "clickhouse",
+			dsn:    "tcp://?debug=true",
+		},
+		{
+			name:   "unsupported",
+			fields: contracts.Fields{"driver": "oracle"},
+			err:    UnsupportedDriverErr,
+		},
+	}
+	for _, item := range cases {
+		t.Run(item.name, func(t *testing.T) {
+			driver, dsn, err := DSN(item.fields)
+			if !errors.Is(err, item.err) {
+				t.Fatalf("err = %v, want %v", err, item.err)
+			}
+			if driver != item.driver || dsn != item.dsn {
+				t.Errorf("DSN = %q, %q, want %q, %q", driver, dsn, item.driver, item.dsn)
+			}
+		})
+	}
+}
+
+func TestPing(t *testing.T) {
+	var (
+		dir      = t.TempDir()
+		existing = filepath.Join(dir, "existing.db")
+		missing  = filepath.Join(dir, "missing.db")
+	)
+	if err := os.WriteFile(existing, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name   string
+		fields contracts.Fields
+		ok     bool
+	}{
+		{"existing sqlite file", contracts.Fields{"driver": "sqlite", "database": existing}, true},
+		{"missing sqlite file", contracts.Fields{"driver": "sqlite", "database": missing}, false},
+		{"unsupported driver", contracts.Fields{"driver": "oracle"}, false},
+		{"unreachable mysql", contracts.Fields{"driver": "mysql", "host": "127.0.0.1", "port": "1", "database": "goal"}, false},
+	}
+	for _, item := range cases {
+		t.Run(item.name, func(t *testing.T) {
+			if err := Ping(context.Background(), item.fields); (err == nil) != item.ok {
+				t.Errorf("err = %v, want ok = %v", err, item.ok)
+			}
+		})
+	}
+
+	if _, err := os.Stat(missing); !os.IsNotExist(err) {
+		t.Errorf("ping created the missing sqlite file: %v", err)
+	}
+}
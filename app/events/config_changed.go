@@ -0,0 +1,62 @@
+package events
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ConfigChanged 配置热加载后触发，Changes 为变化的配置项，例如 mail.Mailers.default.password
+// 监听器应用变化后调用 Apply 标记，没有被标记的配置项需要重启才能生效
+type ConfigChanged struct {
+	Changes []string
+	applied map[string]bool
+	mutex   sync.Mutex
+}
+
+func NewConfigChanged(changes []string) *ConfigChanged {
+	return &ConfigChanged{Changes: changes, applied: make(map[string]bool)}
+}
+
+func (event *ConfigChanged) Event() string {
+	return "CONFIG_CHANGED"
+}
+
+// Sync 同步执行，触发方需要在监听器执行完之后得知哪些配置需要重启
+func (event *ConfigChanged) Sync() bool {
+	return true
+}
+
+// Changed 获取给定前缀下变化的配置项，例如 mail、app.Debug
+func (event *ConfigChanged) Changed(prefix string) []string {
+	var changes = make([]string, 0)
+	for _, change := range event.Changes {
+		if change == prefix || strings.HasPrefix(change, prefix+".") {
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+// Apply 标记给定前缀下的配置项已经生效
+func (event *ConfigChanged) Apply(prefix string) {
+	event.mutex.Lock()
+	defer event.mutex.Unlock()
+	for _, change := range event.Changed(prefix) {
+		event.applied[change] = true
+	}
+}
+
+// RequiresRestart 没有监听器应用的配置项
+func (event *ConfigChanged) RequiresRestart() []string {
+	event.mutex.Lock()
+	defer event.mutex.Unlock()
+	var changes = make([]string, 0)
+	for _, change := range event.Changes {
+		if !event.applied[change] {
+			changes = append(changes, change)
+		}
+	}
+	sort.Strings(changes)
+	return changes
+}
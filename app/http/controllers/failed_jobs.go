@@ -0,0 +1,101 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/goal/app/queue"
+	"github.com/goal-web/http"
+	"time"
+)
+
+// FailedJobs 失败的任务列表，queue 参数只看指定队列
+func FailedJobs(failed *queue.FailedJobs, request contracts.HttpRequest) any {
+	jobs, err := failed.All(request.GetString("queue"))
+	if err != nil {
+		return failedJobsError(err)
+	}
+	return contracts.Fields{"jobs": jobs}
+}
+
+// FailedJob 单个失败的任务，包括完整的 payload 和异常信息
+func FailedJob(failed *queue.FailedJobs, request contracts.HttpRequest) any {
+	jobs, err := failed.Lookup(request.Param("id"))
+	if err != nil {
+		return failedJobsError(err)
+	}
+	return contracts.Fields{"job": jobs[0]}
+}
+
+// RetryFailedJobs ids 为 all、单个 id、逗号分隔的 id 或者 id 范围
+func RetryFailedJobs(failed *queue.FailedJobs, request contracts.HttpRequest) any {
+	jobs, err := failed.Lookup(requestIDs(request))
+	if err != nil {
+		return failedJobsError(err)
+	}
+	retried, err := failed.Retry(jobs...)
+	if err != nil {
+		return http.JsonResponse(contracts.Fields{"retried": retried, "error": err.Error()}, 500)
+	}
+	return contracts.Fields{"retried": retried}
+}
+
+// ForgetFailedJobs 删除路径或者 ids 参数中的任务
+func ForgetFailedJobs(failed *queue.FailedJobs, request contracts.HttpRequest) any {
+	var ids = request.Param("id")
+	if ids == "" {
+		ids = requestIDs(request)
+	}
+	parsed, err := queue.ParseIDs(ids)
+	if err != nil {
+		return failedJobsError(err)
+	}
+	deleted, err := failed.Forget(parsed...)
+	if err != nil {
+		return failedJobsError(err)
+	}
+	return contracts.Fields{"deleted": deleted}
+}
+
+// FlushFailedJobs 删除失败时间早于 days 天的任务，days 必须大于 0，接口不支持清空所有记录
+func FlushFailedJobs(failed *queue.FailedJobs, request contracts.HttpRequest) any {
+	var days = request.IntOptional("days", 0)
+	if days <= 0 {
+		return http.JsonResponse(contracts.Fields{"error": "days 必须大于 0"}, 422)
+	}
+	deleted, err := failed.Flush(time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		return failedJobsError(err)
+	}
+	return contracts.Fields{"deleted": deleted}
+}
+
+// requestIDs ids 可以是字符串、数字或者数组，Get 不读取 json 请求体，所以从 Fields 中取
+func requestIDs(request contracts.HttpRequest) string {
+	switch ids := request.Fields()["ids"].(type) {
+	case []any:
+		var value string
+		for i, id := range ids {
+			if i > 0 {
+				value += ","
+			}
+			value += fmt.Sprint(id)
+		}
+		return value
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(ids)
+	}
+}
+
+func failedJobsError(err error) any {
+	var status = 500
+	switch {
+	case errors.Is(err, queue.FailedJobNotFoundErr):
+		status = 404
+	case errors.Is(err, queue.InvalidIDsErr):
+		status = 422
+	}
+	return http.JsonResponse(contracts.Fields{"error": err.Error()}, status)
+}
@@ -0,0 +1,21 @@
+package controllers
+
+import (
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/goal/app/providers"
+	"github.com/goal-web/http"
+)
+
+// Healthz 存活检查，总是返回 200，status 为 degraded 时说明有依赖不可用
+func Healthz(health *providers.Health, request contracts.HttpRequest) any {
+	return http.JsonResponse(health.Check(request.Request().Context()))
+}
+
+// Readyz 就绪检查，有依赖不可用或者正在关闭时返回 503
+func Readyz(health *providers.Health, request contracts.HttpRequest) any {
+	report := health.Ready(request.Request().Context())
+	if !report.Healthy() {
+		return http.JsonResponse(report, 503)
+	}
+	return http.JsonResponse(report)
+}
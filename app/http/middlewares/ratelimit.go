@@ -0,0 +1,21 @@
+package middlewares
+
+import (
+	"fmt"
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/goal/config"
+	"go.uber.org/ratelimit"
+)
+
+// RateLimit 按 config/ratelimit.go 中 name 对应的速率限流，超过时等待，速率为 0 时不限流
+// 每个请求都读取当前的配置，热加载修改速率后按新的速率创建限流器
+func RateLimit(name string) any {
+	return func(request contracts.HttpRequest, next contracts.Pipe, conf contracts.Config, limiter contracts.RateLimiter) any {
+		if rate := conf.Get("ratelimit").(config.RateLimitConfig).Rate(name); rate > 0 {
+			limiter.Limiter(fmt.Sprintf("http:%s:%d", name, rate), func() contracts.Limiter {
+				return ratelimit.New(rate)
+			}).Take()
+		}
+		return next(request)
+	}
+}
@@ -0,0 +1,15 @@
+package middlewares
+
+import (
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/goal/app/keyring"
+	"github.com/goal-web/http"
+)
+
+// ValidSignature 只允许 keyring.SignURL 签名并且没有过期的链接访问，例如邮件中的确认、下载链接
+func ValidSignature(request contracts.HttpRequest, next contracts.Pipe, keys *keyring.Keyring) any {
+	if err := keys.VerifyURL(request.Request().URL); err != nil {
+		return http.JsonResponse(contracts.Fields{"error": err.Error()}, 403)
+	}
+	return next(request)
+}
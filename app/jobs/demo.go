@@ -2,6 +2,7 @@ package jobs
 
 import (
 	"github.com/goal-web/contracts"
+	appqueue "github.com/goal-web/goal/app/queue"
 	"github.com/goal-web/queue"
 	"github.com/goal-web/supports/class"
 	"github.com/goal-web/supports/logs"
@@ -31,6 +32,18 @@ func NewDemo(info string) contracts.Job {
 	}
 }
 
+// Backoff 失败后依次等待 5 秒、10 秒、20 秒，最多等待一分钟
+func (demo *Demo) Backoff() appqueue.Backoff {
+	return appqueue.Exponential(5*time.Second, time.Minute)
+}
+
+// Middleware 每个 worker 进程每秒最多执行 100 个 demo 任务
+func (demo *Demo) Middleware() []appqueue.JobMiddleware {
+	return []appqueue.JobMiddleware{
+		appqueue.RateLimited("demo", 100, time.Second),
+	}
+}
+
 func (demo *Demo) Handle() {
 
 	logs.Default().WithField("info", demo.Info).Info("demo job")
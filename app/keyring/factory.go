@@ -0,0 +1,37 @@
+package keyring
+
+import (
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/supports/utils"
+	"sync"
+)
+
+// Factory 加密器工厂，default 为应用密钥的 Keyring
+type Factory struct {
+	encryptors sync.Map
+}
+
+func NewFactory(keyring *Keyring) contracts.EncryptorFactory {
+	factory := &Factory{}
+	factory.Extend("default", keyring)
+	return factory
+}
+
+func (factory *Factory) Encode(value string) string {
+	return factory.Driver("default").Encode(value)
+}
+
+func (factory *Factory) Decode(payload string) (string, error) {
+	return factory.Driver("default").Decode(payload)
+}
+
+func (factory *Factory) Extend(key string, encryptor contracts.Encryptor) {
+	factory.encryptors.Store(key, encryptor)
+}
+
+func (factory *Factory) Driver(key string) contracts.Encryptor {
+	if encryptor, exists := factory.encryptors.Load(utils.StringOr(key, "default")); exists {
+		return encryptor.(contracts.Encryptor)
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package keyring
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+var (
+	EmptyKeyErr         = errors.New("应用密钥为空，请执行 key:generate")
+	InvalidKeyErr       = errors.New("应用密钥格式有误")
+	InvalidPayloadErr   = errors.New("密文无效或者加密它的密钥已经不在 app.previous_keys 中")
+	InvalidSignatureErr = errors.New("签名无效")
+)
+
+// Base64Prefix 以此开头的密钥为 base64 编码的随机字节，key:generate 生成的就是这种格式
+const Base64Prefix = "base64:"
+
+// Keyring 使用当前密钥加密、签名，使用当前密钥和旧密钥解密、验签
+// 用旧密钥解出的值重新加密后即换成了当前密钥，例如 session cookie 会在下次响应时重新写入
+// 框架自带的加密器（AES-CBC）加密的旧数据也能解密，见 legacy.go
+type Keyring struct {
+	keys    [][]byte
+	ciphers []cipher.AEAD
+	legacy  []legacyCipher
+	mutex   sync.RWMutex
+}
+
+// New 第一个密钥为当前密钥，其余为旧密钥
+func New(current string, previous ...string) (*Keyring, error) {
+	keyring := &Keyring{}
+	if err := keyring.Replace(current, previous...); err != nil {
+		return nil, err
+	}
+	return keyring, nil
+}
+
+// Replace 替换密钥，用于热加载配置后轮换密钥，任意密钥有误时保持原来的密钥不变
+func (keyring *Keyring) Replace(current string, previous ...string) error {
+	if current == "" {
+		return EmptyKeyErr
+	}
+	var (
+		keys    [][]byte
+		ciphers []cipher.AEAD
+		legacy  []legacyCipher
+	)
+	for _, key := range append([]string{current}, previous...) {
+		if key == "" {
+			continue
+		}
+		material, err := Parse(key)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(material) // 任意长度的密钥都派生为 AES-256 密钥
+		block, _ := aes.NewCipher(sum[:])
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, material)
+		ciphers = append(ciphers, aead)
+		if old, ok := newLegacyCipher(key); ok {
+			legacy = append(legacy, old)
+		}
+	}
+
+	keyring.mutex.Lock()
+	defer keyring.mutex.Unlock()
+	keyring.keys, keyring.ciphers, keyring.legacy = keys, ciphers, legacy
+	return nil
+}
+
+// Generate 生成 32 字节的随机密钥
+func Generate() string {
+	var key = make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic(err)
+	}
+	return Base64Prefix + base64.StdEncoding.EncodeToString(key)
+}
+
+// Parse 解析密钥，base64: 开头的按 base64 解码，其余按原样使用
+func Parse(key string) ([]byte, error) {
+	if !strings.HasPrefix(key, Base64Prefix) {
+		return []byte(key), nil
+	}
+	material, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(key, Base64Prefix))
+	if err != nil {
+		return nil, fmt.Errorf("%w：%v", InvalidKeyErr, err)
+	}
+	return material, nil
+}
+
+// Encode 使用当前密钥加密（AES-256-GCM），结果为 base64 编码的 nonce + 密文
+func (keyring *Keyring) Encode(value string) string {
+	keyring.mutex.RLock()
+	aead := keyring.ciphers[0]
+	keyring.mutex.RUnlock()
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		panic(err)
+	}
+	return base64.StdEncoding.EncodeToString(aead.Seal(nonce, nonce, []byte(value), nil))
+}
+
+// Decode 依次使用当前密钥和旧密钥解密，都失败时再按框架自带加密器的格式解密
+func (keyring *Keyring) Decode(encrypted string) (string, error) {
+	payload, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return "", fmt.Errorf("%w：%v", InvalidPayloadErr, err)
+	}
+	keyring.mutex.RLock()
+	defer keyring.mutex.RUnlock()
+	for _, aead := range keyring.ciphers {
+		if len(payload) < aead.NonceSize() {
+			break
+		}
+		if plaintext, err := aead.Open(nil, payload[:aead.NonceSize()], payload[aead.NonceSize():], nil); err == nil {
+			return string(plaintext), nil
+		}
+	}
+	for _, old := range keyring.legacy {
+		if plaintext, ok := old.decode(payload); ok {
+			return plaintext, nil
+		}
+	}
+	return "", InvalidPayloadErr
+}
+
+// Sign 使用当前密钥签名，结果为 value.签名
+func (keyring *Keyring) Sign(value string) string {
+	return value + "." + keyring.sign(value)
+}
+
+// sign 当前密钥的签名
+func (keyring *Keyring) sign(value string) string {
+	keyring.mutex.RLock()
+	defer keyring.mutex.RUnlock()
+	return signature(keyring.keys[0], value)
+}
+
+// Unsign 依次使用当前密钥和旧密钥验签，返回签名前的值
+func (keyring *Keyring) Unsign(signed string) (string, error) {
+	index := strings.LastIndex(signed, ".")
+	if index < 0 {
+		return "", InvalidSignatureErr
+	}
+	value, sign := signed[:index], signed[index+1:]
+	keyring.mutex.RLock()
+	defer keyring.mutex.RUnlock()
+	for _, key := range keyring.keys {
+		if hmac.Equal([]byte(sign), []byte(signature(key, value))) {
+			return value, nil
+		}
+	}
+	return "", InvalidSignatureErr
+}
+
+func signature(key []byte, value string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(value))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
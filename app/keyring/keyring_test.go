@@ -0,0 +1,244 @@
+package keyring
+
+import (
+	"encoding/base64"
+	"errors"
+	"github.com/goal-web/encryption"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func mustNew(t *testing.T, current string, previous ...string) *Keyring {
+	t.Helper()
+	keyring, err := New(current, previous...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keyring
+}
+
+// tamper 改动密文的最后一个字节
+func tamper(encrypted string) string {
+	payload, _ := base64.StdEncoding.DecodeString(encrypted)
+	payload[len(payload)-1] ^= 1
+	return base64.StdEncoding.EncodeToString(payload)
+}
+
+func TestRotationDecrypt(t *testing.T) {
+	var (
+		oldest = Generate()
+		old    = "plain-text-key"
+		fresh  = Generate()
+
+		byOldest = mustNew(t, oldest).Encode("oldest")
+		byOld    = mustNew(t, old, oldest).Encode("old")
+		byFresh  = mustNew(t, fresh, old).Encode("fresh")
+	)
+
+	cases := []struct {
+		name      string
+		keyring   *Keyring
+		encrypted string
+		expected  string
+		err       error
+	}{
+		{"current key", mustNew(t, fresh, old), byFresh, "fresh", nil},
+		{"previous key", mustNew(t, fresh, old), byOld, "old", nil},
+		{"any previous key", mustNew(t, fresh, old, oldest), byOldest, "oldest", nil},
+		{"dropped previous key", mustNew(t, fresh, old), byOldest, "", InvalidPayloadErr},
+		{"rotated back", mustNew(t, old, fresh), byFresh, "fresh", nil},
+		{"empty previous keys are skipped", mustNew(t, fresh, "", old), byOld, "old", nil},
+		{"not base64", mustNew(t, fresh), "not base64!", "", InvalidPayloadErr},
+		{"too short", mustNew(t, fresh), "AAEC", "", InvalidPayloadErr},
+		{"tampered", mustNew(t, fresh, old), tamper(byFresh), "", InvalidPayloadErr},
+	}
+	for _, item := range cases {
+		t.Run(item.name, func(t *testing.T) {
+			value, err := item.keyring.Decode(item.encrypted)
+			if !errors.Is(err, item.err) {
+				t.Fatalf("err = %v, want %v", err, item.err)
+			}
+			if value != item.expected {
+				t.Errorf("value = %q, want %q", value, item.expected)
+			}
+		})
+	}
+}
+
+func TestLegacyDecrypt(t *testing.T) {
+	var (
+		legacy = "0123456789abcdef0123456789abcdef" // 框架自带的加密器要求密钥为 16、24 或 32 字节
+		short  = "0123456789abcdef"
+		other  = "fedcba9876543210fedcba9876543210"
+
+		// 框架自带的加密器，切换到 GCM 之前 session 等数据都是用它加密的
+		byLegacy = encryption.AES(legacy).Encode(`{"user_id":"1"}`)
+		byShort  = encryption.AES(short).Encode("short")
+	)
+
+	cases := []struct {
+		name      string
+		keyring   *Keyring
+		encrypted string
+		expected  string
+		err       error
+	}{
+		{"current key", mustNew(t, legacy), byLegacy, `{"user_id":"1"}`, nil},
+		{"previous key", mustNew(t, Generate(), legacy), byLegacy, `{"user_id":"1"}`, nil},
+		{"16 bytes key", mustNew(t, Generate(), short), byShort, "short", nil},
+		{"dropped key", mustNew(t, Generate(), other), byLegacy, "", InvalidPayloadErr},
+		{"generated keys are never legacy keys", mustNew(t, Generate()), byLegacy, "", InvalidPayloadErr},
+		{"tampered", mustNew(t, legacy), tamper(byLegacy), "", InvalidPayloadErr},
+	}
+	for _, item := range cases {
+		t.Run(item.name, func(t *testing.T) {
+			value, err := item.keyring.Decode(item.encrypted)
+			if !errors.Is(err, item.err) {
+				t.Fatalf("err = %v, want %v", err, item.err)
+			}
+			if value != item.expected {
+				t.Errorf("value = %q, want %q", value, item.expected)
+			}
+		})
+	}
+
+	t.Run("re-encrypted with GCM", func(t *testing.T) {
+		var (
+			current = Generate()
+			keyring = mustNew(t, current, legacy)
+		)
+		value, err := keyring.Decode(byLegacy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = mustNew(t, current).Decode(keyring.Encode(value)); err != nil {
+			t.Errorf("re-encrypted value can not be decoded without the legacy key: %v", err)
+		}
+	})
+}
+
+func TestEncodeUsesCurrentKey(t *testing.T) {
+	var (
+		old     = Generate()
+		fresh   = Generate()
+		keyring = mustNew(t, old)
+	)
+	encrypted := keyring.Encode("value")
+	if encrypted == keyring.Encode("value") {
+		t.Error("encoding the same value twice gives the same payload")
+	}
+
+	if err := keyring.Replace(fresh, old); err != nil {
+		t.Fatal(err)
+	}
+	// 用旧密钥解出的值重新加密后只需要当前密钥
+	value, err := keyring.Decode(encrypted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = mustNew(t, fresh).Decode(keyring.Encode(value)); err != nil {
+		t.Errorf("re-encrypted value can not be decoded with the current key only: %v", err)
+	}
+}
+
+func TestReplaceKeepsKeysOnError(t *testing.T) {
+	var (
+		current = Generate()
+		keyring = mustNew(t, current)
+	)
+	encrypted := keyring.Encode("value")
+
+	cases := []struct {
+		name     string
+		current  string
+		previous []string
+		err      error
+	}{
+		{"empty current key", "", nil, EmptyKeyErr},
+		{"invalid current key", Base64Prefix + "%%%", nil, InvalidKeyErr},
+		{"invalid previous key", Generate(), []string{Base64Prefix + "%%%"}, InvalidKeyErr},
+	}
+	for _, item := range cases {
+		t.Run(item.name, func(t *testing.T) {
+			if err := keyring.Replace(item.current, item.previous...); !errors.Is(err, item.err) {
+				t.Fatalf("err = %v, want %v", err, item.err)
+			}
+			if value, err := keyring.Decode(encrypted); err != nil || value != "value" {
+				t.Errorf("Decode = %q, %v after a failed Replace", value, err)
+			}
+		})
+	}
+}
+
+func TestSignRotation(t *testing.T) {
+	var (
+		old   = Generate()
+		fresh = Generate()
+	)
+	cases := []struct {
+		name    string
+		signed  string
+		keyring *Keyring
+		err     error
+	}{
+		{"current key", mustNew(t, fresh).Sign("a.b"), mustNew(t, fresh, old), nil},
+		{"previous key", mustNew(t, old).Sign("a.b"), mustNew(t, fresh, old), nil},
+		{"dropped previous key", mustNew(t, old).Sign("a.b"), mustNew(t, fresh), InvalidSignatureErr},
+		{"changed value", strings.Replace(mustNew(t, fresh).Sign("a.b"), "a", "c", 1), mustNew(t, fresh), InvalidSignatureErr},
+		{"no signature", "a", mustNew(t, fresh), InvalidSignatureErr},
+	}
+	for _, item := range cases {
+		t.Run(item.name, func(t *testing.T) {
+			value, err := item.keyring.Unsign(item.signed)
+			if !errors.Is(err, item.err) {
+				t.Fatalf("err = %v, want %v", err, item.err)
+			}
+			if err == nil && value != "a.b" {
+				t.Errorf("value = %q, want %q", value, "a.b")
+			}
+		})
+	}
+}
+
+func TestSignURL(t *testing.T) {
+	var (
+		old     = Generate()
+		keyring = mustNew(t, Generate(), old)
+		sign    = func(keyring *Keyring, link string, expires time.Time) string {
+			signed, err := keyring.SignURL(link, expires)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return signed
+		}
+	)
+	cases := []struct {
+		name string
+		link string
+		err  error
+	}{
+		{"signed", sign(keyring, "https://example.com/download?file=a.txt", time.Time{}), nil},
+		{"parameters in any order", strings.Replace(sign(keyring, "/download?b=2&a=1", time.Time{}), "a=1&b=2", "b=2&a=1", 1), nil},
+		{"signed by a previous key", sign(mustNew(t, old), "/download?file=a.txt", time.Time{}), nil},
+		{"not expired", sign(keyring, "/download", time.Now().Add(time.Hour)), nil},
+		{"expired", sign(keyring, "/download", time.Now().Add(-time.Second)), ExpiredSignatureErr},
+		{"changed parameter", strings.Replace(sign(keyring, "/download?file=a.txt", time.Time{}), "a.txt", "b.txt", 1), InvalidSignatureErr},
+		{"changed path", strings.Replace(sign(keyring, "/download?file=a.txt", time.Time{}), "/download", "/delete", 1), InvalidSignatureErr},
+		{"added parameter", sign(keyring, "/download?file=a.txt", time.Time{}) + "&admin=1", InvalidSignatureErr},
+		{"removed expiry", strings.Replace(sign(keyring, "/download?a=1", time.Now().Add(-time.Second)), "expires=", "x=", 1), InvalidSignatureErr},
+		{"not signed", "/download?file=a.txt", InvalidSignatureErr},
+	}
+	for _, item := range cases {
+		t.Run(item.name, func(t *testing.T) {
+			link, err := url.Parse(item.link)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err = keyring.VerifyURL(link); !errors.Is(err, item.err) {
+				t.Errorf("err = %v, want %v", err, item.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package keyring
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"unicode/utf8"
+)
+
+// legacyCipher 框架自带的加密器：密钥原样作为 AES 密钥（必须是 16、24 或 32 字节），前 16 字节作为 CBC 的 IV
+// 只用于解密切换到 GCM 之前加密的数据，解出的值由调用方用 Encode 重新加密，例如 session cookie 在下次响应时重新写入
+type legacyCipher struct {
+	block cipher.Block
+	iv    []byte
+}
+
+// newLegacyCipher 长度不能直接作为 AES 密钥的密钥不可能被框架自带的加密器使用过
+func newLegacyCipher(key string) (legacyCipher, bool) {
+	block, err := aes.NewCipher([]byte(key))
+	if err != nil {
+		return legacyCipher{}, false
+	}
+	return legacyCipher{block: block, iv: []byte(key)[:aes.BlockSize]}, true
+}
+
+// decode CBC 没有校验，只能通过填充和 utf8 排除密钥不对的情况
+func (legacy legacyCipher) decode(payload []byte) (string, bool) {
+	if len(payload) == 0 || len(payload)%aes.BlockSize != 0 {
+		return "", false
+	}
+	plaintext := make([]byte, len(payload))
+	cipher.NewCBCDecrypter(legacy.block, legacy.iv).CryptBlocks(plaintext, payload)
+
+	padding := int(plaintext[len(plaintext)-1])
+	if padding == 0 || padding > aes.BlockSize ||
+		!bytes.Equal(plaintext[len(plaintext)-padding:], bytes.Repeat([]byte{byte(padding)}, padding)) {
+		return "", false
+	}
+	plaintext = plaintext[:len(plaintext)-padding]
+	if !utf8.Valid(plaintext) {
+		return "", false
+	}
+	return string(plaintext), true
+}
@@ -0,0 +1,53 @@
+package keyring
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+var ExpiredSignatureErr = errors.New("链接已过期")
+
+// SignURL 给链接加上 signature 参数，expires 不为零时同时加上 expires 参数，过期后链接失效
+// 签名覆盖路径和其余参数，不包括域名，使用 VerifyURL 或者 middlewares.ValidSignature 校验
+func (keyring *Keyring) SignURL(link string, expires time.Time) (string, error) {
+	parsed, err := url.Parse(link)
+	if err != nil {
+		return "", err
+	}
+	query := parsed.Query()
+	query.Del("signature")
+	query.Del("expires")
+	if !expires.IsZero() {
+		query.Set("expires", strconv.FormatInt(expires.Unix(), 10))
+	}
+	query.Set("signature", keyring.sign(canonical(parsed.Path, query)))
+	parsed.RawQuery = query.Encode()
+	return parsed.String(), nil
+}
+
+// VerifyURL 校验 SignURL 生成的链接，旧密钥签名的链接在密钥留在 app.previous_keys 期间仍然有效
+func (keyring *Keyring) VerifyURL(link *url.URL) error {
+	query := link.Query()
+	sign := query.Get("signature")
+	query.Del("signature")
+	if _, err := keyring.Unsign(canonical(link.Path, query) + "." + sign); err != nil {
+		return err
+	}
+	if expires := query.Get("expires"); expires != "" {
+		unix, err := strconv.ParseInt(expires, 10, 64)
+		if err != nil {
+			return InvalidSignatureErr
+		}
+		if time.Now().Unix() > unix {
+			return ExpiredSignatureErr
+		}
+	}
+	return nil
+}
+
+// canonical 参与签名的内容，参数按名称排序
+func canonical(path string, query url.Values) string {
+	return path + "?" + query.Encode()
+}
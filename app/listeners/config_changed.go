@@ -0,0 +1,116 @@
+package listeners
+
+import (
+	"github.com/goal-web/application"
+	"github.com/goal-web/cache"
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/email"
+	"github.com/goal-web/goal/app/events"
+	"github.com/goal-web/goal/app/keyring"
+	config2 "github.com/goal-web/goal/config"
+	"github.com/goal-web/supports/logs"
+	"github.com/golang-module/carbon/v2"
+)
+
+// ApplyConfig 应用热加载后变化的配置，没有在这里应用的配置需要重启才能生效
+// 框架的事件分发器会让同一事件的多个监听器都执行最后一个（闭包捕获了循环变量），所以放在一个监听器里依次处理
+type ApplyConfig struct {
+}
+
+func (listener ApplyConfig) Handle(event contracts.Event) {
+	if e, ok := event.(*events.ConfigChanged); ok {
+		applyAppConfig(e)
+		reloadMailers(e)
+		reloadCache(e)
+		rotateKeys(e)
+		applyRateLimits(e)
+	}
+}
+
+// applyAppConfig 热更新日志级别（app.debug）、语言和时区
+func applyAppConfig(e *events.ConfigChanged) {
+	appConfig := application.Get("config").(contracts.Config).Get("app").(application.Config)
+	if len(e.Changed("app.Debug")) > 0 {
+		logs.Debug = appConfig.Debug
+		e.Apply("app.Debug")
+	}
+	if len(e.Changed("app.Locale")) > 0 {
+		carbon.SetLocale(appConfig.Locale)
+		e.Apply("app.Locale")
+	}
+	if len(e.Changed("app.Timezone")) > 0 {
+		carbon.SetTimezone(appConfig.Timezone)
+		e.Apply("app.Timezone")
+	}
+}
+
+// reloadMailers 邮件配置（例如账号密码）变化后重建邮件服务
+func reloadMailers(e *events.ConfigChanged) {
+	if len(e.Changed("mail")) > 0 {
+		rebuild(application.Singleton(), email.NewService(), []string{"mail.factory", "mailer"},
+			share[contracts.Config]("config"),
+			share[contracts.Queue]("queue"),
+		)
+		e.Apply("mail")
+	}
+}
+
+// reloadCache 缓存配置（例如前缀）变化后重建缓存服务，redis 连接的变化仍然需要重启
+func reloadCache(e *events.ConfigChanged) {
+	if len(e.Changed("cache")) > 0 {
+		rebuild(application.Singleton(), cache.NewService(), []string{"cache", "cache.store"},
+			share[contracts.Config]("config"),
+			share[contracts.RedisFactory]("redis.factory"),
+			share[contracts.ExceptionHandler]("exceptions.handler"),
+		)
+		e.Apply("cache")
+	}
+}
+
+// rotateKeys 应用密钥（app.key、app.previous_keys）变化后原地替换密钥，加密服务、session 等持有的都是同一个 Keyring
+func rotateKeys(e *events.ConfigChanged) {
+	if len(e.Changed("encryption")) > 0 {
+		var (
+			app    = application.Singleton()
+			config = app.Get("config").(contracts.Config).Get("encryption").(config2.EncryptionConfig)
+		)
+		if err := app.Get("keyring").(*keyring.Keyring).Replace(config.Key, config.PreviousKeys...); err != nil {
+			logs.WithError(err).Error("listeners.rotateKeys: 密钥有误，继续使用原来的密钥")
+			return
+		}
+		e.Apply("encryption")
+		e.Apply("app.Key")
+	}
+}
+
+// applyRateLimits 接口限流的中间件每个请求都读取当前的速率，不需要重建服务
+func applyRateLimits(e *events.ConfigChanged) {
+	if len(e.Changed("ratelimit")) > 0 {
+		e.Apply("ratelimit")
+	}
+}
+
+// share 重建服务时，把当前应用中的绑定共享给临时应用，T 为绑定的类型，用于按类型注入
+func share[T any](key string) func(scratch, app contracts.Application) {
+	return func(scratch, app contracts.Application) {
+		scratch.Singleton(key, func() T {
+			return app.Get(key).(T)
+		})
+	}
+}
+
+// rebuild 在临时应用中用最新的配置重新注册服务，然后替换当前应用中给定绑定的实例
+// 用于框架自带的、在注册时读取配置并缓存的服务，例如邮件、缓存
+// 已经持有旧实例的对象不受影响
+func rebuild(app contracts.Application, provider contracts.ServiceProvider, keys []string, shares ...func(scratch, app contracts.Application)) {
+	scratch := application.New(app.Debug())
+	for _, share := range shares {
+		share(scratch, app)
+	}
+	provider.Register(scratch)
+
+	for _, key := range keys {
+		app.Get(key) // 确保延迟加载的服务已经注册和启动
+		app.Instance(key, scratch.Get(key))
+	}
+}
@@ -0,0 +1,94 @@
+package locks
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/goal-web/contracts"
+	"time"
+)
+
+var (
+	UnsupportedStoreErr = errors.New("不支持的锁存储")
+	NotOwnerErr         = errors.New("锁已过期或者由其他进程持有")
+)
+
+// DefaultTTL 没有指定过期时间时锁的过期时间，防止进程崩溃后锁永远不被释放
+const DefaultTTL = 24 * time.Hour
+
+// Store 原子锁的存储
+type Store interface {
+	// Add name 不存在时写入 owner 并设置过期时间，已存在时返回 false
+	Add(name, owner string, ttl time.Duration) (bool, error)
+
+	// Release name 的持有者是 owner 时删除锁
+	Release(name, owner string) (bool, error)
+}
+
+// Lock 一把带过期时间的锁，只有持有者能够释放
+type Lock struct {
+	store Store
+	name  string
+	owner string
+	ttl   time.Duration
+}
+
+// New ttl 为 0 时使用 DefaultTTL
+func New(store Store, name string, ttl time.Duration) *Lock {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &Lock{store: store, name: name, owner: randomOwner(), ttl: ttl}
+}
+
+func (lock *Lock) Name() string {
+	return lock.name
+}
+
+func (lock *Lock) Owner() string {
+	return lock.owner
+}
+
+// Acquire 尝试获取锁，不会等待
+func (lock *Lock) Acquire() (bool, error) {
+	return lock.store.Add(lock.name, lock.owner, lock.ttl)
+}
+
+// Release 释放自己持有的锁，锁已过期并被其他进程获取时返回 NotOwnerErr
+func (lock *Lock) Release() error {
+	released, err := lock.store.Release(lock.name, lock.owner)
+	if err != nil {
+		return err
+	}
+	if !released {
+		return fmt.Errorf("%w：%s", NotOwnerErr, lock.name)
+	}
+	return nil
+}
+
+// Resolve 从容器中获取锁存储，store 为 redis 或者 cache，connection 为 redis 连接名或者缓存存储名，为空时使用默认连接
+func Resolve(app contracts.Application, store, connection, prefix string) (Store, error) {
+	switch store {
+	case "", "redis":
+		return NewRedisStore(app.Get("redis.factory").(contracts.RedisFactory).Connection(names(connection)...), prefix), nil
+	case "cache":
+		return NewCacheStore(app.Get("cache").(contracts.CacheFactory).Store(names(connection)...), prefix), nil
+	default:
+		return nil, fmt.Errorf("%w：%s", UnsupportedStoreErr, store)
+	}
+}
+
+// names 连接名为空时使用默认连接
+func names(name string) []string {
+	if name == "" {
+		return nil
+	}
+	return []string{name}
+}
+
+func randomOwner() string {
+	var bytes = make([]byte, 16)
+	_, _ = rand.Read(bytes)
+	return hex.EncodeToString(bytes)
+}
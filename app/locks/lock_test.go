@@ -0,0 +1,141 @@
+package locks
+
+import (
+	"errors"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/goal-web/application"
+	"github.com/goal-web/cache/drivers"
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/redis"
+	"testing"
+	"time"
+)
+
+// redisConfig 只提供 redis 配置
+type redisConfig struct {
+	contracts.Config
+	redis redis.Config
+}
+
+func (config redisConfig) Get(string) any {
+	return config.redis
+}
+
+// ignoreExceptions redis 服务需要注入异常处理器
+type ignoreExceptions struct {
+	contracts.ExceptionHandler
+}
+
+// newApp 注册了连接到内存 redis 的 redis 服务
+func newApp(t *testing.T) (contracts.Application, *miniredis.Miniredis) {
+	t.Helper()
+	var (
+		server = miniredis.RunT(t)
+		app    = application.New()
+	)
+	app.Singleton("config", func() contracts.Config {
+		return redisConfig{redis: redis.Config{
+			Default: "default",
+			Stores:  map[string]contracts.Fields{"default": {"host": server.Host(), "port": server.Port()}},
+		}}
+	})
+	app.Singleton("exceptions.handler", func() contracts.ExceptionHandler { return ignoreExceptions{} })
+	redis.NewService().Register(app)
+	return app, server
+}
+
+func TestStores(t *testing.T) {
+	cases := []struct {
+		name  string
+		store func(app contracts.Application) Store
+		key   string
+	}{
+		{
+			name: "redis",
+			store: func(app contracts.Application) Store {
+				store, err := Resolve(app, "redis", "", "locks:")
+				if err != nil {
+					t.Fatal(err)
+				}
+				return store
+			},
+			key: "locks:task",
+		},
+		{
+			name: "cache",
+			store: func(app contracts.Application) Store {
+				return NewCacheStore(drivers.NewRedisCache(app.Get("redis.factory").(contracts.RedisFactory).Connection(), "cache:"), "locks:")
+			},
+			key: "cache:locks:task",
+		},
+	}
+	for _, item := range cases {
+		t.Run(item.name+" only one owner", func(t *testing.T) {
+			var (
+				app, server = newApp(t)
+				store       = item.store(app)
+				first       = New(store, "task", time.Minute)
+				second      = New(store, "task", time.Minute)
+			)
+			if acquired, err := first.Acquire(); err != nil || !acquired {
+				t.Fatalf("first Acquire = %v, %v", acquired, err)
+			}
+			if owner, _ := server.Get(item.key); owner != first.Owner() {
+				t.Errorf("owner = %q, want %q", owner, first.Owner())
+			}
+			if acquired, err := second.Acquire(); err != nil || acquired {
+				t.Fatalf("second Acquire = %v, %v while the lock is held", acquired, err)
+			}
+			if err := second.Release(); !errors.Is(err, NotOwnerErr) {
+				t.Errorf("Release by another owner err = %v, want %v", err, NotOwnerErr)
+			}
+			if err := first.Release(); err != nil {
+				t.Fatal(err)
+			}
+			if acquired, err := second.Acquire(); err != nil || !acquired {
+				t.Errorf("second Acquire = %v, %v after release", acquired, err)
+			}
+		})
+
+		t.Run(item.name+" expires", func(t *testing.T) {
+			var (
+				app, server = newApp(t)
+				store       = item.store(app)
+				first       = New(store, "task", time.Minute)
+				second      = New(store, "task", time.Minute)
+			)
+			if acquired, _ := first.Acquire(); !acquired {
+				t.Fatal("first Acquire failed")
+			}
+			server.FastForward(time.Minute + time.Second)
+			if acquired, err := second.Acquire(); err != nil || !acquired {
+				t.Fatalf("second Acquire = %v, %v after the lock expired", acquired, err)
+			}
+			if err := first.Release(); !errors.Is(err, NotOwnerErr) {
+				t.Errorf("Release of an expired lock err = %v, want %v", err, NotOwnerErr)
+			}
+			if owner, _ := server.Get(item.key); owner != second.Owner() {
+				t.Errorf("owner = %q after the expired owner released, want %q", owner, second.Owner())
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	var (
+		lock  = New(nil, "task", 0)
+		other = New(nil, "task", time.Second)
+	)
+	if lock.ttl != DefaultTTL {
+		t.Errorf("ttl = %v, want %v", lock.ttl, DefaultTTL)
+	}
+	if lock.Owner() == "" || lock.Owner() == other.Owner() {
+		t.Errorf("owners %q and %q are not unique", lock.Owner(), other.Owner())
+	}
+}
+
+func TestResolveUnsupportedStore(t *testing.T) {
+	if _, err := Resolve(nil, "memcached", "", ""); !errors.Is(err, UnsupportedStoreErr) {
+		t.Errorf("err = %v, want %v", err, UnsupportedStoreErr)
+	}
+}
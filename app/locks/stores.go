@@ -0,0 +1,53 @@
+package locks
+
+import (
+	"fmt"
+	"github.com/goal-web/contracts"
+	"time"
+)
+
+// RedisStore 通过 SET NX 获取锁，通过 lua 脚本比较持有者后删除锁，两者都是原子的
+type RedisStore struct {
+	redis  contracts.RedisConnection
+	prefix string
+}
+
+func NewRedisStore(redis contracts.RedisConnection, prefix string) *RedisStore {
+	return &RedisStore{redis: redis, prefix: prefix}
+}
+
+func (store *RedisStore) Add(name, owner string, ttl time.Duration) (bool, error) {
+	return store.redis.SetNX(store.prefix+name, owner, ttl)
+}
+
+const releaseScript = `if redis.call("get", KEYS[1]) == ARGV[1] then return redis.call("del", KEYS[1]) else return 0 end`
+
+func (store *RedisStore) Release(name, owner string) (bool, error) {
+	result, err := store.redis.Eval(releaseScript, []string{store.prefix + name}, owner)
+	if err != nil {
+		return false, err
+	}
+	return result == int64(1), nil
+}
+
+// CacheStore 通过缓存的 Add 获取锁，需要使用 redis 等多个进程共享的缓存存储
+// 缓存接口没有比较后删除的操作，释放时先读取再删除
+type CacheStore struct {
+	cache  contracts.CacheStore
+	prefix string
+}
+
+func NewCacheStore(cache contracts.CacheStore, prefix string) *CacheStore {
+	return &CacheStore{cache: cache, prefix: prefix}
+}
+
+func (store *CacheStore) Add(name, owner string, ttl time.Duration) (bool, error) {
+	return store.cache.Add(store.prefix+name, owner, ttl), nil
+}
+
+func (store *CacheStore) Release(name, owner string) (bool, error) {
+	if fmt.Sprint(store.cache.Get(store.prefix+name)) != owner {
+		return false, nil
+	}
+	return true, store.cache.Forget(store.prefix + name)
+}
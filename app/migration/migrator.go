@@ -0,0 +1,265 @@
+package migration
+
+import (
+	"errors"
+	"fmt"
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/database/drivers"
+	"github.com/goal-web/supports/exceptions"
+	"sort"
+)
+
+var (
+	MigrationNotFoundErr = errors.New("迁移文件不存在")
+	MigrationFailedErr   = errors.New("迁移失败")
+)
+
+// Table 记录已执行迁移的表
+const Table = "migrations"
+
+// Migration 一个版本的迁移，Name 以日期开头，按名称排序执行，例如 2023_04_14_000001_create_users_table
+type Migration struct {
+	Name string
+	Up   func(schema *Schema) error
+	Down func(schema *Schema) error
+}
+
+// Status 迁移状态，Batch 为 0 表示还没有执行
+type Status struct {
+	Name  string `json:"migration"`
+	Batch int    `json:"batch"`
+}
+
+// Ran 是否已经执行
+func (status Status) Ran() bool {
+	return status.Batch > 0
+}
+
+type record struct {
+	Migration string `db:"migration"`
+	Batch     int    `db:"batch"`
+}
+
+// Migrator 执行迁移并记录到 migrations 表，同一次执行的迁移为一个批次，回滚时按批次回滚
+type Migrator struct {
+	connection contracts.DBConnection
+	schema     *Schema
+	migrations []Migration
+}
+
+func NewMigrator(connection contracts.DBConnection, migrations []Migration) (*Migrator, error) {
+	schema, err := NewSchema(connection, connection.DriverName())
+	if err != nil {
+		return nil, err
+	}
+	sorted := append([]Migration{}, migrations...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+	return &Migrator{connection: connection, schema: schema, migrations: sorted}, nil
+}
+
+// Run 执行所有未执行的迁移，step 为 true 时每个迁移单独一个批次，便于逐个回滚
+func (migrator *Migrator) Run(step bool) ([]string, error) {
+	if err := migrator.prepare(); err != nil {
+		return nil, err
+	}
+	records, err := migrator.records()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		ran   = make(map[string]bool)
+		batch = 0
+		names = make([]string, 0)
+	)
+	for _, item := range records {
+		ran[item.Migration] = true
+		if item.Batch > batch {
+			batch = item.Batch
+		}
+	}
+	batch++
+
+	for _, migration := range migrator.migrations {
+		if ran[migration.Name] {
+			continue
+		}
+		err = migrator.run(migration.Up, func(executor contracts.SqlExecutor) error {
+			_, e := executor.Exec(fmt.Sprintf("insert into %s (migration, batch) values (?, ?)", migrator.schema.grammar.quote(Table)), migration.Name, batch)
+			return exceptions.WithError(e)
+		})
+		if err != nil {
+			return names, fmt.Errorf("%w：%s：%v", MigrationFailedErr, migration.Name, err)
+		}
+		names = append(names, migration.Name)
+		if step {
+			batch++
+		}
+	}
+	return names, nil
+}
+
+// Rollback steps 为 0 时回滚最后一个批次，否则回滚最后 steps 个迁移
+func (migrator *Migrator) Rollback(steps int) ([]string, error) {
+	if err := migrator.prepare(); err != nil {
+		return nil, err
+	}
+	records, err := migrator.records()
+	if err != nil || len(records) == 0 {
+		return nil, err
+	}
+
+	var targets = make([]record, 0)
+	for i := len(records) - 1; i >= 0; i-- {
+		if steps > 0 && len(targets) >= steps {
+			break
+		}
+		if steps == 0 && records[i].Batch != records[len(records)-1].Batch {
+			break
+		}
+		targets = append(targets, records[i])
+	}
+
+	var names = make([]string, 0, len(targets))
+	for _, target := range targets {
+		migration, exists := migrator.find(target.Migration)
+		if !exists {
+			return names, fmt.Errorf("%w：%s", MigrationNotFoundErr, target.Migration)
+		}
+		err = migrator.run(migration.Down, func(executor contracts.SqlExecutor) error {
+			_, e := executor.Exec(fmt.Sprintf("delete from %s where migration = ?", migrator.schema.grammar.quote(Table)), migration.Name)
+			return exceptions.WithError(e)
+		})
+		if err != nil {
+			return names, fmt.Errorf("%w：%s：%v", MigrationFailedErr, migration.Name, err)
+		}
+		names = append(names, migration.Name)
+	}
+	return names, nil
+}
+
+// Status 所有迁移文件以及 migrations 表中找不到迁移文件的记录
+func (migrator *Migrator) Status() ([]Status, error) {
+	if err := migrator.prepare(); err != nil {
+		return nil, err
+	}
+	records, err := migrator.records()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		batches  = make(map[string]int)
+		statuses = make([]Status, 0, len(migrator.migrations))
+	)
+	for _, item := range records {
+		batches[item.Migration] = item.Batch
+	}
+	for _, migration := range migrator.migrations {
+		statuses = append(statuses, Status{Name: migration.Name, Batch: batches[migration.Name]})
+		delete(batches, migration.Name)
+	}
+	for _, item := range records {
+		if _, missing := batches[item.Migration]; missing {
+			statuses = append(statuses, Status{Name: item.Migration, Batch: item.Batch})
+		}
+	}
+	return statuses, nil
+}
+
+// Fresh 删除所有表后重新执行所有迁移
+func (migrator *Migrator) Fresh() ([]string, error) {
+	if err := migrator.schema.DropAll(); err != nil {
+		return nil, err
+	}
+	return migrator.Run(false)
+}
+
+func (migrator *Migrator) find(name string) (Migration, bool) {
+	for _, migration := range migrator.migrations {
+		if migration.Name == name {
+			return migration, true
+		}
+	}
+	return Migration{}, false
+}
+
+// prepare 创建 migrations 表
+func (migrator *Migrator) prepare() error {
+	exists, err := migrator.schema.HasTable(Table)
+	if err != nil || exists {
+		return err
+	}
+	return migrator.schema.Create(Table, func(table *Blueprint) {
+		table.ID()
+		table.String("migration")
+		table.Integer("batch")
+	})
+}
+
+// records 按执行顺序排列的迁移记录
+func (migrator *Migrator) records() ([]record, error) {
+	var records = make([]record, 0)
+	if err := migrator.connection.Select(&records, fmt.Sprintf("select migration, batch from %s order by batch, id", migrator.schema.grammar.quote(Table))); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// run 在事务中执行迁移并更新 migrations 表，mysql 的 DDL 会隐式提交，所以不使用事务
+func (migrator *Migrator) run(migrate func(schema *Schema) error, log func(executor contracts.SqlExecutor) error) error {
+	if migrate == nil {
+		migrate = func(schema *Schema) error { return nil }
+	}
+	if migrator.schema.Driver() == "mysql" {
+		if err := migrate(migrator.schema); err != nil {
+			return err
+		}
+		return log(migrator.connection)
+	}
+
+	var err error
+	exception := migrator.connection.Transaction(func(executor contracts.SqlExecutor) contracts.Exception {
+		if migrator.schema.Driver() == "postgres" {
+			executor = postgresTx{executor}
+		}
+		schema := &Schema{executor: executor, grammar: migrator.schema.grammar}
+		if err = migrate(schema); err != nil {
+			return exceptions.WithError(err)
+		}
+		if err = log(executor); err != nil {
+			return exceptions.WithError(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if exception != nil {
+		return exception
+	}
+	return nil
+}
+
+// postgresTx 框架的事务没有像 postgres 连接那样把 ? 占位符转换为 $1、$2
+type postgresTx struct {
+	contracts.SqlExecutor
+}
+
+func (tx postgresTx) Query(query string, args ...any) (contracts.Collection[contracts.Fields], contracts.Exception) {
+	return tx.SqlExecutor.Query(drivers.DollarNParamBindWrapper(query), args...)
+}
+
+func (tx postgresTx) Get(dest any, query string, args ...any) contracts.Exception {
+	return tx.SqlExecutor.Get(dest, drivers.DollarNParamBindWrapper(query), args...)
+}
+
+func (tx postgresTx) Select(dest any, query string, args ...any) contracts.Exception {
+	return tx.SqlExecutor.Select(dest, drivers.DollarNParamBindWrapper(query), args...)
+}
+
+func (tx postgresTx) Exec(query string, args ...any) (contracts.Result, contracts.Exception) {
+	return tx.SqlExecutor.Exec(drivers.DollarNParamBindWrapper(query), args...)
+}
@@ -0,0 +1,175 @@
+package migration
+
+import (
+	"errors"
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/database/drivers"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func create(name, table string) Migration {
+	return Migration{
+		Name: name,
+		Up: func(schema *Schema) error {
+			return schema.Create(table, func(table *Blueprint) {
+				table.ID()
+				table.String("name")
+				table.Timestamps()
+			})
+		},
+		Down: func(schema *Schema) error {
+			return schema.Drop(table)
+		},
+	}
+}
+
+// newMigrator 使用临时 sqlite 文件
+func newMigrator(t *testing.T, migrations ...Migration) *Migrator {
+	connection := drivers.SqliteConnector(contracts.Fields{"database": filepath.Join(t.TempDir(), "test.db")}, nil)
+	migrator, err := NewMigrator(connection, migrations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return migrator
+}
+
+func assertTables(t *testing.T, migrator *Migrator, expected map[string]bool) {
+	t.Helper()
+	for table, exists := range expected {
+		has, err := migrator.schema.HasTable(table)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if has != exists {
+			t.Errorf("table %s exists = %v, want %v", table, has, exists)
+		}
+	}
+}
+
+func assertBatches(t *testing.T, migrator *Migrator, expected []int) {
+	t.Helper()
+	statuses, err := migrator.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var batches = make([]int, 0, len(statuses))
+	for _, status := range statuses {
+		batches = append(batches, status.Batch)
+	}
+	if !reflect.DeepEqual(batches, expected) {
+		t.Errorf("batches = %v, want %v", batches, expected)
+	}
+}
+
+func TestMigratorRoundTrip(t *testing.T) {
+	migrator := newMigrator(t,
+		create("2023_01_01_000003_create_comments_table", "comments"),
+		create("2023_01_01_000001_create_users_table", "users"),
+		create("2023_01_01_000002_create_posts_table", "posts"),
+	)
+
+	steps := []struct {
+		name    string
+		action  func() ([]string, error)
+		names   []string
+		batches []int
+		tables  map[string]bool
+	}{
+		{
+			name:    "run in one batch, sorted by name",
+			action:  func() ([]string, error) { return migrator.Run(false) },
+			names:   []string{"2023_01_01_000001_create_users_table", "2023_01_01_000002_create_posts_table", "2023_01_01_000003_create_comments_table"},
+			batches: []int{1, 1, 1},
+			tables:  map[string]bool{"users": true, "posts": true, "comments": true},
+		},
+		{
+			name:    "nothing left to run",
+			action:  func() ([]string, error) { return migrator.Run(false) },
+			names:   []string{},
+			batches: []int{1, 1, 1},
+		},
+		{
+			name:    "rollback the last batch in reverse order",
+			action:  func() ([]string, error) { return migrator.Rollback(0) },
+			names:   []string{"2023_01_01_000003_create_comments_table", "2023_01_01_000002_create_posts_table", "2023_01_01_000001_create_users_table"},
+			batches: []int{0, 0, 0},
+			tables:  map[string]bool{"users": false, "posts": false, "comments": false},
+		},
+		{
+			name:    "run with step gives each migration its own batch",
+			action:  func() ([]string, error) { return migrator.Run(true) },
+			names:   []string{"2023_01_01_000001_create_users_table", "2023_01_01_000002_create_posts_table", "2023_01_01_000003_create_comments_table"},
+			batches: []int{1, 2, 3},
+			tables:  map[string]bool{"users": true, "posts": true, "comments": true},
+		},
+		{
+			name:    "rollback the last batch only",
+			action:  func() ([]string, error) { return migrator.Rollback(0) },
+			names:   []string{"2023_01_01_000003_create_comments_table"},
+			batches: []int{1, 2, 0},
+			tables:  map[string]bool{"posts": true, "comments": false},
+		},
+		{
+			name:    "rollback a number of steps",
+			action:  func() ([]string, error) { return migrator.Rollback(2) },
+			names:   []string{"2023_01_01_000002_create_posts_table", "2023_01_01_000001_create_users_table"},
+			batches: []int{0, 0, 0},
+			tables:  map[string]bool{"users": false, "posts": false},
+		},
+		{
+			name:    "rollback with nothing ran",
+			action:  func() ([]string, error) { return migrator.Rollback(0) },
+			names:   nil,
+			batches: []int{0, 0, 0},
+		},
+		{
+			name:    "fresh runs everything again",
+			action:  migrator.Fresh,
+			names:   []string{"2023_01_01_000001_create_users_table", "2023_01_01_000002_create_posts_table", "2023_01_01_000003_create_comments_table"},
+			batches: []int{1, 1, 1},
+			tables:  map[string]bool{"users": true, "posts": true, "comments": true},
+		},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			names, err := step.action()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(names, step.names) {
+				t.Errorf("names = %v, want %v", names, step.names)
+			}
+			assertBatches(t, migrator, step.batches)
+			assertTables(t, migrator, step.tables)
+		})
+	}
+}
+
+func TestMigratorFailedMigrationIsRolledBack(t *testing.T) {
+	failed := errors.New("failed")
+	migrator := newMigrator(t,
+		create("2023_01_01_000001_create_users_table", "users"),
+		Migration{
+			Name: "2023_01_01_000002_create_posts_table",
+			Up: func(schema *Schema) error {
+				if err := create("", "posts").Up(schema); err != nil {
+					return err
+				}
+				return failed
+			},
+		},
+	)
+
+	names, err := migrator.Run(false)
+	if !errors.Is(err, MigrationFailedErr) {
+		t.Fatalf("err = %v, want %v", err, MigrationFailedErr)
+	}
+	if !reflect.DeepEqual(names, []string{"2023_01_01_000001_create_users_table"}) {
+		t.Errorf("names = %v", names)
+	}
+	assertBatches(t, migrator, []int{1, 0})
+	assertTables(t, migrator, map[string]bool{"users": true, "posts": false})
+}
@@ -0,0 +1,361 @@
+package migration
+
+import (
+	"errors"
+	"fmt"
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/supports/exceptions"
+	"strings"
+)
+
+var UnsupportedDriverErr = errors.New("迁移不支持该数据库驱动")
+
+// Expression 原样写入 SQL 的默认值，例如 Expression("CURRENT_TIMESTAMP")
+type Expression string
+
+// Schema 按数据库驱动生成建表、删表等语句，迁移的 Up、Down 通过它修改表结构
+type Schema struct {
+	executor contracts.SqlExecutor
+	grammar  grammar
+}
+
+// NewSchema driver 为 sqlx 的驱动名：sqlite3、mysql 或者 postgres
+func NewSchema(executor contracts.SqlExecutor, driver string) (*Schema, error) {
+	switch driver {
+	case "sqlite3", "mysql", "postgres":
+		return &Schema{executor: executor, grammar: grammar{driver: driver}}, nil
+	default:
+		return nil, fmt.Errorf("%w：%s", UnsupportedDriverErr, driver)
+	}
+}
+
+// Driver 当前数据库驱动，用于编写只适用于某种数据库的迁移
+func (schema *Schema) Driver() string {
+	return schema.grammar.driver
+}
+
+// Create 建表
+func (schema *Schema) Create(table string, define func(table *Blueprint)) error {
+	var blueprint = &Blueprint{table: table}
+	define(blueprint)
+
+	var columns = make([]string, 0, len(blueprint.columns))
+	for _, column := range blueprint.columns {
+		columns = append(columns, schema.grammar.column(column))
+	}
+	statements := []string{fmt.Sprintf("create table %s (%s)", schema.grammar.quote(table), strings.Join(columns, ", "))}
+
+	return schema.exec(append(statements, schema.grammar.indexes(blueprint)...)...)
+}
+
+// Table 修改表，添加字段和索引
+func (schema *Schema) Table(table string, define func(table *Blueprint)) error {
+	var (
+		blueprint  = &Blueprint{table: table}
+		statements = make([]string, 0)
+	)
+	define(blueprint)
+
+	for _, column := range blueprint.columns {
+		statements = append(statements, fmt.Sprintf("alter table %s add column %s", schema.grammar.quote(table), schema.grammar.column(column)))
+	}
+
+	return schema.exec(append(statements, schema.grammar.indexes(blueprint)...)...)
+}
+
+// Drop 删表，表不存在时忽略
+func (schema *Schema) Drop(table string) error {
+	var statement = "drop table if exists " + schema.grammar.quote(table)
+	if schema.grammar.driver == "postgres" {
+		statement += " cascade"
+	}
+	return schema.exec(statement)
+}
+
+// HasTable 表是否存在
+func (schema *Schema) HasTable(table string) (bool, error) {
+	var count int
+	var query = map[string]string{
+		"sqlite3":  "select count(*) from sqlite_master where type = 'table' and name = ?",
+		"mysql":    "select count(*) from information_schema.tables where table_schema = database() and table_name = ?",
+		"postgres": "select count(*) from information_schema.tables where table_schema = current_schema() and table_name = ?",
+	}[schema.grammar.driver]
+	if err := schema.executor.Get(&count, query, table); err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// Tables 当前数据库中的所有表
+func (schema *Schema) Tables() ([]string, error) {
+	var tables = make([]string, 0)
+	var query = map[string]string{
+		"sqlite3":  "select name from sqlite_master where type = 'table' and name not like 'sqlite_%'",
+		"mysql":    "select table_name from information_schema.tables where table_schema = database() and table_type = 'BASE TABLE'",
+		"postgres": "select tablename from pg_tables where schemaname = current_schema()",
+	}[schema.grammar.driver]
+	if err := schema.executor.Select(&tables, query); err != nil {
+		return nil, err
+	}
+	return tables, nil
+}
+
+// DropAll 删除所有表，用于 migrate:fresh
+// mysql 的 foreign_key_checks 只对当前连接有效，所以在一个事务（同一个连接）中删表，并在连接放回连接池之前恢复
+func (schema *Schema) DropAll() error {
+	connection, ok := schema.executor.(contracts.DBConnection)
+	if !ok || schema.grammar.driver != "mysql" {
+		return schema.dropAll()
+	}
+	var err error
+	exception := connection.Transaction(func(executor contracts.SqlExecutor) contracts.Exception {
+		err = (&Schema{executor: executor, grammar: schema.grammar}).dropAll()
+		return exceptions.WithError(err)
+	})
+	if err != nil {
+		return err
+	}
+	if exception != nil {
+		return exception
+	}
+	return nil
+}
+
+func (schema *Schema) dropAll() (err error) {
+	tables, err := schema.Tables()
+	if err != nil {
+		return err
+	}
+	if schema.grammar.driver == "mysql" {
+		if err = schema.exec("set foreign_key_checks = 0"); err != nil {
+			return err
+		}
+		defer func() {
+			if restoreErr := schema.exec("set foreign_key_checks = 1"); err == nil {
+				err = restoreErr
+			}
+		}()
+	}
+	for _, table := range tables {
+		if err = schema.Drop(table); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Exec 执行原生 SQL
+func (schema *Schema) Exec(statement string, args ...any) error {
+	if _, err := schema.executor.Exec(statement, args...); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (schema *Schema) exec(statements ...string) error {
+	for _, statement := range statements {
+		if err := schema.Exec(statement); err != nil {
+			return fmt.Errorf("%s：%w", statement, err)
+		}
+	}
+	return nil
+}
+
+// Blueprint 表结构
+type Blueprint struct {
+	table   string
+	columns []*Column
+	indexes []index
+}
+
+type index struct {
+	unique  bool
+	columns []string
+}
+
+func (blueprint *Blueprint) add(name, kind string, length int) *Column {
+	column := &Column{name: name, kind: kind, length: length}
+	blueprint.columns = append(blueprint.columns, column)
+	return column
+}
+
+// ID 自增主键 id
+func (blueprint *Blueprint) ID() *Column {
+	return blueprint.add("id", "id", 0)
+}
+
+// String 变长字符串，默认长度 255
+func (blueprint *Blueprint) String(name string, length ...int) *Column {
+	if len(length) == 0 {
+		length = []int{255}
+	}
+	return blueprint.add(name, "string", length[0])
+}
+
+func (blueprint *Blueprint) Text(name string) *Column {
+	return blueprint.add(name, "text", 0)
+}
+
+func (blueprint *Blueprint) Integer(name string) *Column {
+	return blueprint.add(name, "integer", 0)
+}
+
+func (blueprint *Blueprint) BigInteger(name string) *Column {
+	return blueprint.add(name, "bigInteger", 0)
+}
+
+func (blueprint *Blueprint) Boolean(name string) *Column {
+	return blueprint.add(name, "boolean", 0)
+}
+
+func (blueprint *Blueprint) Timestamp(name string) *Column {
+	return blueprint.add(name, "timestamp", 0)
+}
+
+// Json sqlite 中存为 text
+func (blueprint *Blueprint) Json(name string) *Column {
+	return blueprint.add(name, "json", 0)
+}
+
+// Timestamps 可为空的 created_at、updated_at
+func (blueprint *Blueprint) Timestamps() {
+	blueprint.Timestamp("created_at").Nullable()
+	blueprint.Timestamp("updated_at").Nullable()
+}
+
+// Index 普通索引
+func (blueprint *Blueprint) Index(columns ...string) {
+	blueprint.indexes = append(blueprint.indexes, index{columns: columns})
+}
+
+// Unique 唯一索引
+func (blueprint *Blueprint) Unique(columns ...string) {
+	blueprint.indexes = append(blueprint.indexes, index{unique: true, columns: columns})
+}
+
+// Column 字段定义
+type Column struct {
+	name         string
+	kind         string
+	length       int
+	nullable     bool
+	defaultValue any
+	hasDefault   bool
+	primary      bool
+}
+
+// Nullable 允许为空
+func (column *Column) Nullable() *Column {
+	column.nullable = true
+	return column
+}
+
+// Default 默认值，字符串会被转义，原样写入请使用 Expression
+func (column *Column) Default(value any) *Column {
+	column.defaultValue, column.hasDefault = value, true
+	return column
+}
+
+// Primary 主键，用于非自增的主键，例如 sessions.id
+func (column *Column) Primary() *Column {
+	column.primary = true
+	return column
+}
+
+type grammar struct {
+	driver string
+}
+
+func (grammar grammar) quote(name string) string {
+	if grammar.driver == "mysql" {
+		return "`" + name + "`"
+	}
+	return `"` + name + `"`
+}
+
+func (grammar grammar) column(column *Column) string {
+	var definition = grammar.quote(column.name) + " " + grammar.columnType(column)
+	if column.kind == "id" {
+		return definition
+	}
+	if column.primary {
+		definition += " primary key"
+	}
+	if column.nullable {
+		definition += " null"
+	} else {
+		definition += " not null"
+	}
+	if column.hasDefault {
+		definition += " default " + grammar.value(column.defaultValue)
+	}
+	return definition
+}
+
+func (grammar grammar) columnType(column *Column) string {
+	switch column.kind {
+	case "id":
+		return map[string]string{
+			"sqlite3":  "integer primary key autoincrement",
+			"mysql":    "bigint unsigned not null auto_increment primary key",
+			"postgres": "bigserial primary key",
+		}[grammar.driver]
+	case "string":
+		return fmt.Sprintf("varchar(%d)", column.length)
+	case "bigInteger":
+		return "bigint"
+	case "boolean":
+		if grammar.driver == "mysql" {
+			return "tinyint(1)"
+		}
+		return "boolean"
+	case "timestamp":
+		if grammar.driver == "sqlite3" {
+			return "datetime"
+		}
+		return "timestamp"
+	case "json":
+		return map[string]string{"sqlite3": "text", "mysql": "json", "postgres": "jsonb"}[grammar.driver]
+	default: // text、integer
+		return column.kind
+	}
+}
+
+func (grammar grammar) value(value any) string {
+	switch v := value.(type) {
+	case Expression:
+		return string(v)
+	case string:
+		return "'" + strings.ReplaceAll(v, "'", "''") + "'"
+	case bool:
+		if grammar.driver == "postgres" {
+			return fmt.Sprint(v)
+		}
+		if v {
+			return "1"
+		}
+		return "0"
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// indexes 索引名为 表名_字段名_index 或者 表名_字段名_unique
+func (grammar grammar) indexes(blueprint *Blueprint) []string {
+	var statements = make([]string, 0, len(blueprint.indexes))
+	for _, item := range blueprint.indexes {
+		var (
+			kind, suffix = "index", "_index"
+			columns      = make([]string, 0, len(item.columns))
+		)
+		if item.unique {
+			kind, suffix = "unique index", "_unique"
+		}
+		for _, column := range item.columns {
+			columns = append(columns, grammar.quote(column))
+		}
+		name := blueprint.table + "_" + strings.Join(item.columns, "_") + suffix
+		statements = append(statements, fmt.Sprintf("create %s %s on %s (%s)", kind, grammar.quote(name), grammar.quote(blueprint.table), strings.Join(columns, ", ")))
+	}
+	return statements
+}
@@ -0,0 +1,106 @@
+package migration
+
+import (
+	"errors"
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/supports/exceptions"
+	"reflect"
+	"testing"
+)
+
+// recorder 记录执行的语句以及执行它的连接，Transaction 中的语句记为 tx
+type recorder struct {
+	contracts.DBConnection
+	name   string
+	tables []string
+	fail   string
+	log    *[]string
+}
+
+func (executor *recorder) Select(dest any, _ string, _ ...any) contracts.Exception {
+	*dest.(*[]string) = executor.tables
+	return nil
+}
+
+func (executor *recorder) Exec(query string, _ ...any) (contracts.Result, contracts.Exception) {
+	*executor.log = append(*executor.log, executor.name+": "+query)
+	if query == executor.fail {
+		return nil, exceptions.New("failed")
+	}
+	return nil, nil
+}
+
+func (executor *recorder) Transaction(fn func(executor contracts.SqlExecutor) contracts.Exception) contracts.Exception {
+	tx := *executor
+	tx.name = "tx"
+	return fn(&tx)
+}
+
+func TestDropAll(t *testing.T) {
+	cases := []struct {
+		name     string
+		driver   string
+		fail     string
+		expected []string
+		err      bool
+	}{
+		{
+			name:   "mysql drops on one connection with foreign key checks off",
+			driver: "mysql",
+			expected: []string{
+				"tx: set foreign_key_checks = 0",
+				"tx: drop table if exists `users`",
+				"tx: drop table if exists `posts`",
+				"tx: set foreign_key_checks = 1",
+			},
+		},
+		{
+			name:   "mysql restores foreign key checks when a drop fails",
+			driver: "mysql",
+			fail:   "drop table if exists `users`",
+			expected: []string{
+				"tx: set foreign_key_checks = 0",
+				"tx: drop table if exists `users`",
+				"tx: set foreign_key_checks = 1",
+			},
+			err: true,
+		},
+		{
+			name:   "sqlite",
+			driver: "sqlite3",
+			expected: []string{
+				`pool: drop table if exists "users"`,
+				`pool: drop table if exists "posts"`,
+			},
+		},
+		{
+			name:   "postgres",
+			driver: "postgres",
+			expected: []string{
+				`pool: drop table if exists "users" cascade`,
+				`pool: drop table if exists "posts" cascade`,
+			},
+		},
+	}
+	for _, item := range cases {
+		t.Run(item.name, func(t *testing.T) {
+			var log = make([]string, 0)
+			schema, err := NewSchema(&recorder{name: "pool", tables: []string{"users", "posts"}, fail: item.fail, log: &log}, item.driver)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err = schema.DropAll(); (err != nil) != item.err {
+				t.Fatalf("err = %v, want error: %v", err, item.err)
+			}
+			if !reflect.DeepEqual(log, item.expected) {
+				t.Errorf("statements = %q, want %q", log, item.expected)
+			}
+		})
+	}
+}
+
+func TestNewSchemaUnsupportedDriver(t *testing.T) {
+	if _, err := NewSchema(nil, "clickhouse"); !errors.Is(err, UnsupportedDriverErr) {
+		t.Errorf("err = %v, want %v", err, UnsupportedDriverErr)
+	}
+}
@@ -0,0 +1,16 @@
+package migration
+
+import "time"
+
+// timeLayouts 各数据库驱动返回的时间格式：sqlite 带时区，mysql 未开启 parseTime 时为字符串，postgres 为 RFC3339
+var timeLayouts = []string{time.RFC3339Nano, "2006-01-02 15:04:05.999999999-07:00", "2006-01-02 15:04:05.999999999"}
+
+// ParseTime 把读取为字符串的 timestamp 字段解析为时间，无法解析时返回零值
+func ParseTime(value string) time.Time {
+	for _, layout := range timeLayouts {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed
+		}
+	}
+	return time.Time{}
+}
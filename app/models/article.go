@@ -15,6 +15,8 @@ func ArticleQuery() *table.Table[Article] {
 }
 
 type Article struct {
-	Id     string `json:"id"`
-	UserId string `json:"user_id"`
+	Id      string `json:"id"`
+	UserId  string `json:"user_id"`
+	Title   string `json:"title"`
+	Content string `json:"content"`
 }
@@ -0,0 +1,48 @@
+package app
+
+// Profile 进程角色，决定启动时注册哪些服务提供者
+type Profile string
+
+const (
+	Http      Profile = "http"      // http、websocket、sse 服务
+	Worker    Profile = "worker"    // 队列消费者
+	Scheduler Profile = "scheduler" // 任务调度
+	Micro     Profile = "micro"     // 微服务服务端
+	Cli       Profile = "cli"       // 控制台命令
+	Test      Profile = "test"      // 单元测试
+)
+
+// Profiles 当前进程选中的角色集合
+type Profiles []Profile
+
+// Has 判断是否选中了给定角色中的任意一个
+func (profiles Profiles) Has(targets ...Profile) bool {
+	for _, profile := range profiles {
+		for _, target := range targets {
+			if profile == target {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// servers 常驻进程的角色，需要监听退出信号
+var servers = []Profile{Http, Worker, Scheduler, Micro}
+
+// roles 常驻命令与其需要的角色，其余命令按 Cli 角色启动
+var roles = map[string]Profiles{
+	"run":           servers,
+	"serve":         {Http},
+	"queue:work":    {Worker},
+	"schedule:work": {Scheduler},
+	"micro:serve":   {Micro},
+}
+
+// ProfilesOf 获取运行给定命令需要的角色
+func ProfilesOf(command string) Profiles {
+	if profiles, exists := roles[command]; exists {
+		return profiles
+	}
+	return Profiles{Cli}
+}
@@ -0,0 +1,112 @@
+package app
+
+import (
+	"github.com/goal-web/auth"
+	"github.com/goal-web/bloomfilter"
+	"github.com/goal-web/cache"
+	"github.com/goal-web/config"
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/database"
+	"github.com/goal-web/email"
+	"github.com/goal-web/events"
+	"github.com/goal-web/filesystem"
+	"github.com/goal-web/goal/app/console"
+	"github.com/goal-web/goal/app/providers"
+	config2 "github.com/goal-web/goal/config"
+	"github.com/goal-web/goal/routes"
+	"github.com/goal-web/hashing"
+	"github.com/goal-web/ratelimiter"
+	"github.com/goal-web/redis"
+	"github.com/goal-web/serialization"
+	"github.com/goal-web/session"
+	"syscall"
+)
+
+// service 服务清单中的一项
+type service struct {
+	profiles []Profile // 为空表示所有角色都需要
+	provider func(profiles Profiles) contracts.ServiceProvider
+}
+
+// always 所有角色都需要的服务
+func always(provider contracts.ServiceProvider) service {
+	return service{provider: func(Profiles) contracts.ServiceProvider {
+		return provider
+	}}
+}
+
+// only 仅指定角色需要的服务
+func only(provider contracts.ServiceProvider, profiles ...Profile) service {
+	return service{profiles: profiles, provider: func(Profiles) contracts.ServiceProvider {
+		return provider
+	}}
+}
+
+// depends 根据选中的角色决定如何构造的服务
+func depends(provider func(profiles Profiles) contracts.ServiceProvider, profiles ...Profile) service {
+	return service{profiles: profiles, provider: provider}
+}
+
+// manifest 服务清单，新增服务提供者只需要在这里添加
+// 注册顺序由各服务声明的依赖决定，见 providers.Sort
+// 通过 providers.Defer 标记的服务在其绑定第一次被解析时才注册和启动
+// 通过 providers.Checked 或者实现 providers.HealthChecker 的服务会出现在 /healthz、/readyz 中
+func manifest(env contracts.Env) []service {
+	return []service{
+		always(providers.Declare("config", config.NewService(env, config2.GetConfigProviders()))),
+		always(providers.Declare("hashing", hashing.NewService(), "config")),
+		always(providers.NewEncryption()),
+		always(providers.Declare("filesystem", filesystem.NewService(), "config")),
+		always(providers.Declare("serialization", serialization.NewService(), "config")),
+		always(providers.Declare("events", events.NewService())),
+		always(providers.NewEvents()),
+		always(providers.Defer(providers.Checked(providers.Declare("redis", redis.NewService(), "config"), providers.RedisChecks),
+			providers.Provides[contracts.RedisFactory]("redis.factory"),
+			providers.Provides[contracts.RedisConnection]("redis"),
+			providers.Provides[*redis.Connection]("redis.connection"),
+		)),
+		always(providers.Defer(providers.Declare("cache", cache.NewService(), "config", "redis"),
+			providers.Provides[contracts.CacheFactory]("cache"),
+			providers.Provides[contracts.CacheStore]("cache.store"),
+		)),
+		always(providers.Defer(providers.Declare("bloomfilter", bloomfilter.NewService(), "config", "redis"),
+			providers.Provides[contracts.BloomFactory]("bloom.factory"),
+			providers.Provides[contracts.BloomFilter]("bloom.filter"),
+		)),
+		always(providers.Declare("auth", auth.NewService(), "config", "redis", "database", "session")),
+		always(providers.Declare("ratelimiter", ratelimiter.NewService())),
+		always(providers.Declare("console", console.NewService(), "config", "redis")),
+		only(providers.NewScheduling(), Scheduler),
+		always(providers.Defer(providers.Checked(providers.Declare("database", database.NewService(), "config", "events"), providers.DatabaseChecks),
+			providers.Provides[contracts.DBFactory]("db.factory"),
+			providers.Provides[contracts.DBConnection]("db"),
+		)),
+		depends(func(profiles Profiles) contracts.ServiceProvider {
+			return providers.NewQueue(profiles.Has(Worker))
+		}),
+		always(providers.Defer(providers.Checked(providers.Declare("email", email.NewService(), "config", "queue"), providers.MailChecks),
+			providers.Provides[contracts.EmailFactory]("mail.factory"),
+			providers.Provides[contracts.Mailer]("mailer"),
+		)),
+		only(providers.NewHttp(routes.All()...), Http),
+		always(providers.Declare("session", session.NewService(), "config", "redis", "encryption")),
+		only(providers.NewSse(), Http),
+		only(providers.NewWebSocket(), Http),
+		depends(func(profiles Profiles) contracts.ServiceProvider {
+			return providers.NewMicro(profiles.Has(Micro))
+		}, Http, Micro, Cli),
+		only(providers.NewShutdown(syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT), servers...),
+		only(providers.NewReload(config2.Reload, syscall.SIGHUP), servers...),
+	}
+}
+
+// Providers 获取给定角色需要的服务提供者，并按依赖关系排序
+func Providers(env contracts.Env, profiles ...Profile) ([]contracts.ServiceProvider, error) {
+	var results = make([]contracts.ServiceProvider, 0)
+	for _, item := range manifest(env) {
+		if len(item.profiles) == 0 || Profiles(profiles).Has(item.profiles...) {
+			results = append(results, item.provider(profiles))
+		}
+	}
+	return providers.Sort(results)
+}
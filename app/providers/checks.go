@@ -0,0 +1,98 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/database"
+	"github.com/goal-web/email"
+	"github.com/goal-web/goal/app/dbcheck"
+	"github.com/goal-web/redis"
+	"github.com/goal-web/supports/utils"
+	"net"
+)
+
+var NoAddressErr = errors.New("没有配置地址")
+
+// DatabaseChecks 检查默认连接以及所有配置了地址的数据库连接
+// 直接按配置建立连接并 ping，不经过 db.factory，框架的数据库驱动连接失败时会直接退出进程
+func DatabaseChecks(app contracts.Application) map[string]Check {
+	var (
+		checks = make(map[string]Check)
+		config = app.Get("config").(contracts.Config).Get("database").(database.Config)
+	)
+	for name, fields := range config.Connections {
+		if name != config.Default && !configured(fields, "host", "dsn", "database") {
+			continue
+		}
+		checks["database."+name] = func(fields contracts.Fields) Check {
+			return func(ctx context.Context) error {
+				return dbcheck.Ping(ctx, fields)
+			}
+		}(fields)
+	}
+	return checks
+}
+
+// RedisChecks 对所有 redis 存储执行 ping
+func RedisChecks(app contracts.Application) map[string]Check {
+	var (
+		checks = make(map[string]Check)
+		config = app.Get("config").(contracts.Config).Get("redis").(redis.Config)
+	)
+	for name := range config.Stores {
+		checks["redis."+name] = func(name string) Check {
+			return func(ctx context.Context) error {
+				_, err := app.Get("redis.factory").(contracts.RedisFactory).Connection(name).CommandWithContext(ctx, "ping")
+				return err
+			}
+		}(name)
+	}
+	return checks
+}
+
+// MailChecks 检查所有配置了地址的邮件服务器能否连通
+func MailChecks(app contracts.Application) map[string]Check {
+	var (
+		checks = make(map[string]Check)
+		config = app.Get("config").(contracts.Config).Get("mail").(email.Config)
+	)
+	for name, fields := range config.Mailers {
+		if !configured(fields, "host") {
+			continue
+		}
+		address := net.JoinHostPort(utils.GetStringField(fields, "host"), utils.GetStringField(fields, "port", "25"))
+		checks["mail."+name] = func(ctx context.Context) error {
+			return dial(ctx, address)
+		}
+	}
+	return checks
+}
+
+// configured 给定的字段中至少有一个不为空
+func configured(fields contracts.Fields, keys ...string) bool {
+	for _, key := range keys {
+		if utils.ToString(fields[key], "") != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// dial 依次尝试连接给定地址，有一个能连通即可
+func dial(ctx context.Context, addresses ...string) error {
+	var (
+		dialer net.Dialer
+		err    = NoAddressErr
+	)
+	for _, address := range addresses {
+		if address == "" {
+			continue
+		}
+		var conn net.Conn
+		if conn, err = dialer.DialContext(ctx, "tcp", address); err == nil {
+			return conn.Close()
+		}
+	}
+	return err
+}
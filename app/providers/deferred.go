@@ -0,0 +1,135 @@
+package providers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/supports/logs"
+	"sync"
+)
+
+var DeferredBindingErr = errors.New("延迟服务没有注册声明的绑定")
+
+// Binding 延迟服务声明提供的单例绑定，第一次被解析时构造实例并启动服务
+type Binding struct {
+	key  string
+	wrap func(app contracts.Application, provider any, load func())
+}
+
+// Provides 声明延迟服务提供的单例绑定，T 为绑定的类型，用于按类型注入
+// 同时解析的调用方等待同一个实例构造完成，构造时 panic 的话下一次解析会重新构造
+func Provides[T any](key string) Binding {
+	return Binding{key: key, wrap: func(app contracts.Application, provider any, load func()) {
+		var (
+			mutex    sync.Mutex
+			built    bool
+			instance T
+		)
+		app.Singleton(key, func() T {
+			mutex.Lock()
+			defer mutex.Unlock()
+			if !built {
+				instance = app.Call(provider)[0].(T)
+				built = true
+				load() // 只标记并异步启动服务，不会等待这把锁
+			}
+			return instance
+		})
+	}}
+}
+
+type deferred struct {
+	Dependent
+	bindings []Binding
+	once     sync.Once
+	mutex    sync.Mutex
+	loaded   bool
+	started  bool
+}
+
+// Defer 将服务标记为延迟加载，服务在启动时注册，但是声明的绑定第一次被解析时才会构造实例和启动服务
+// 不会被用到的服务（例如短命令用不到的 redis、数据库）不再拖慢启动或者因为无法连接而失败
+// 容器的绑定只在启动时写入，运行时解析不会和其他 goroutine 并发写容器
+func Defer(provider Dependent, bindings ...Binding) Dependent {
+	return &deferred{Dependent: provider, bindings: bindings}
+}
+
+func (provider *deferred) Register(app contracts.Application) {
+	registrar := &deferredRegistrar{Application: app, provider: provider, registered: map[string]bool{}}
+	provider.Dependent.Register(registrar)
+	for _, binding := range provider.bindings {
+		if !registrar.registered[binding.key] {
+			panic(fmt.Errorf("%w：%s：%s", DeferredBindingErr, provider.Name(), binding.key))
+		}
+	}
+}
+
+// load 标记服务已被用到，如果应用已经启动则同时启动服务
+func (provider *deferred) load() {
+	provider.once.Do(func() {
+		logs.Default().Debug(fmt.Sprintf("providers.deferred: loading %s", provider.Name()))
+
+		provider.mutex.Lock()
+		provider.loaded = true
+		started := provider.started
+		provider.mutex.Unlock()
+
+		if started {
+			go provider.start()
+		}
+	})
+}
+
+func (provider *deferred) start() {
+	if err := provider.Dependent.Start(); err != nil {
+		logs.WithError(err).Error(fmt.Sprintf("providers.deferred: %s start failed", provider.Name()))
+	}
+}
+
+func (provider *deferred) Start() error {
+	provider.mutex.Lock()
+	provider.started = true
+	loaded := provider.loaded
+	provider.mutex.Unlock()
+
+	if loaded {
+		return provider.Dependent.Start()
+	}
+	return nil
+}
+
+func (provider *deferred) Stop() {
+	provider.mutex.Lock()
+	loaded := provider.loaded
+	provider.mutex.Unlock()
+
+	if loaded {
+		provider.Dependent.Stop()
+	}
+}
+
+// HealthChecks 转发被延迟服务的健康检查，检查时才会加载服务
+func (provider *deferred) HealthChecks(app contracts.Application) map[string]Check {
+	if checker, ok := provider.Dependent.(HealthChecker); ok {
+		return checker.HealthChecks(app)
+	}
+	return nil
+}
+
+// deferredRegistrar 注册时把声明的单例包装成第一次解析时才构造的绑定，其余绑定原样注册
+type deferredRegistrar struct {
+	contracts.Application
+	provider   *deferred
+	registered map[string]bool
+}
+
+func (registrar *deferredRegistrar) Singleton(key string, provider any) {
+	for _, binding := range registrar.provider.bindings {
+		if binding.key == key {
+			binding.wrap(registrar.Application, provider, registrar.provider.load)
+			registrar.registered[key] = true
+			return
+		}
+	}
+	registrar.Application.Singleton(key, provider)
+}
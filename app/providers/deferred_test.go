@@ -0,0 +1,166 @@
+package providers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/goal-web/application"
+	"github.com/goal-web/contracts"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// connection 延迟服务提供的实例
+type connection struct {
+	id int32
+}
+
+// lazy 记录构造、启动、停止次数的服务，failures 次构造会 panic
+type lazy struct {
+	builds   atomic.Int32
+	starts   atomic.Int32
+	stops    atomic.Int32
+	failures atomic.Int32
+	started  chan struct{}
+}
+
+func newLazy() *lazy {
+	return &lazy{started: make(chan struct{}, 1)}
+}
+
+func (provider *lazy) Register(app contracts.Application) {
+	app.Singleton("connection", func() *connection {
+		if provider.failures.Add(-1) >= 0 {
+			panic("connect failed")
+		}
+		time.Sleep(10 * time.Millisecond) // 让同时解析的调用方等待
+		return &connection{id: provider.builds.Add(1)}
+	})
+	app.Singleton("eager", func() string { return "eager" })
+}
+
+func (provider *lazy) Start() error {
+	provider.starts.Add(1)
+	provider.started <- struct{}{}
+	return nil
+}
+
+func (provider *lazy) Stop() {
+	provider.stops.Add(1)
+}
+
+func newDeferred(provider *lazy) (contracts.Application, contracts.ServiceProvider) {
+	var (
+		app     = application.New()
+		service = Defer(Declare("connection", provider), Provides[*connection]("connection"))
+	)
+	service.Register(app)
+	return app, service
+}
+
+func TestDeferUnused(t *testing.T) {
+	var (
+		provider     = newLazy()
+		app, service = newDeferred(provider)
+	)
+	if eager := app.Get("eager"); eager != "eager" {
+		t.Errorf("eager = %v, other bindings are registered as is", eager)
+	}
+	if err := service.Start(); err != nil {
+		t.Fatal(err)
+	}
+	service.Stop()
+	if builds, starts, stops := provider.builds.Load(), provider.starts.Load(), provider.stops.Load(); builds+starts+stops != 0 {
+		t.Errorf("builds = %d, starts = %d, stops = %d, want an unused service untouched", builds, starts, stops)
+	}
+}
+
+func TestDeferLoad(t *testing.T) {
+	cases := []struct {
+		name    string
+		resolve func(app contracts.Application, service contracts.ServiceProvider)
+	}{
+		{"resolved before start", func(app contracts.Application, service contracts.ServiceProvider) {
+			app.Get("connection")
+			_ = service.Start()
+		}},
+		{"resolved after start", func(app contracts.Application, service contracts.ServiceProvider) {
+			_ = service.Start()
+			app.Get("connection")
+		}},
+	}
+	for _, item := range cases {
+		t.Run(item.name, func(t *testing.T) {
+			var (
+				provider     = newLazy()
+				app, service = newDeferred(provider)
+			)
+			item.resolve(app, service)
+			select {
+			case <-provider.started:
+			case <-time.After(time.Second):
+				t.Fatal("service not started")
+			}
+			app.Get("connection")
+			service.Stop()
+			if builds, starts, stops := provider.builds.Load(), provider.starts.Load(), provider.stops.Load(); builds != 1 || starts != 1 || stops != 1 {
+				t.Errorf("builds = %d, starts = %d, stops = %d, want 1 each", builds, starts, stops)
+			}
+		})
+	}
+}
+
+func TestDeferConcurrentResolve(t *testing.T) {
+	var (
+		provider = newLazy()
+		app, _   = newDeferred(provider)
+		wg       sync.WaitGroup
+		results  = make([]*connection, 8)
+	)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = app.Get("connection").(*connection)
+		}(i)
+	}
+	wg.Wait()
+	for _, result := range results {
+		if result != results[0] {
+			t.Fatalf("resolved different instances %v and %v", result, results[0])
+		}
+	}
+	if builds := provider.builds.Load(); builds != 1 {
+		t.Errorf("builds = %d, want 1", builds)
+	}
+}
+
+func TestDeferBuildPanics(t *testing.T) {
+	var (
+		provider = newLazy()
+		app, _   = newDeferred(provider)
+	)
+	provider.failures.Store(1)
+	func() {
+		defer func() {
+			if recovered := recover(); recovered == nil {
+				t.Fatal("first resolve did not panic")
+			}
+		}()
+		app.Get("connection")
+	}()
+	if result := app.Get("connection").(*connection); result == nil || result.id != 1 {
+		t.Errorf("resolved %v after a failed build, want a new instance", result)
+	}
+}
+
+func TestDeferMissingBinding(t *testing.T) {
+	defer func() {
+		err, _ := recover().(error)
+		if !errors.Is(err, DeferredBindingErr) || err.Error() != fmt.Sprintf("%s：connection：missing", DeferredBindingErr) {
+			t.Errorf("panic = %v, want %v", err, DeferredBindingErr)
+		}
+	}()
+	Defer(Declare("connection", newLazy()), Provides[*connection]("missing")).Register(application.New())
+}
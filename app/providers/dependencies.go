@@ -0,0 +1,113 @@
+package providers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/goal-web/contracts"
+	"strings"
+)
+
+var (
+	MissingDependencyErr  = errors.New("服务依赖缺失")
+	CircularDependencyErr = errors.New("服务存在循环依赖")
+	DuplicateProviderErr  = errors.New("服务重复注册")
+)
+
+// Dependent 声明了名称和依赖的服务提供者
+type Dependent interface {
+	contracts.ServiceProvider
+
+	// Name 服务名称，供其他服务声明依赖
+	Name() string
+
+	// Dependencies 依赖的服务名称
+	Dependencies() []string
+}
+
+type declared struct {
+	contracts.ServiceProvider
+	name         string
+	dependencies []string
+}
+
+// Declare 为没有实现 Dependent 的服务提供者（例如框架自带的服务）声明名称和依赖
+func Declare(name string, provider contracts.ServiceProvider, dependencies ...string) Dependent {
+	return &declared{ServiceProvider: provider, name: name, dependencies: dependencies}
+}
+
+func (provider *declared) Name() string {
+	return provider.name
+}
+
+func (provider *declared) Dependencies() []string {
+	return provider.dependencies
+}
+
+// Sort 按依赖关系排序服务提供者，被依赖的服务排在前面，其余保持原有顺序
+// 依赖缺失、循环依赖或者重名时返回错误
+func Sort(services []contracts.ServiceProvider) ([]contracts.ServiceProvider, error) {
+	var (
+		named   = make(map[string]Dependent)
+		missing = make([]string, 0)
+	)
+	for _, service := range services {
+		if dependent, ok := service.(Dependent); ok {
+			if _, exists := named[dependent.Name()]; exists {
+				return nil, fmt.Errorf("%w：%s", DuplicateProviderErr, dependent.Name())
+			}
+			named[dependent.Name()] = dependent
+		}
+	}
+	for _, service := range services {
+		if dependent, ok := service.(Dependent); ok {
+			for _, dependency := range dependent.Dependencies() {
+				if _, exists := named[dependency]; !exists {
+					missing = append(missing, fmt.Sprintf("%s 依赖 %s", dependent.Name(), dependency))
+				}
+			}
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w：%s", MissingDependencyErr, strings.Join(missing, "，"))
+	}
+
+	var (
+		sorted   = make([]contracts.ServiceProvider, 0, len(services))
+		visited  = make(map[string]bool)
+		visiting = make([]string, 0)
+		visit    func(dependent Dependent) error
+	)
+	visit = func(dependent Dependent) error {
+		if visited[dependent.Name()] {
+			return nil
+		}
+		for index, name := range visiting {
+			if name == dependent.Name() {
+				path := append(visiting[index:], name)
+				return fmt.Errorf("%w：%s", CircularDependencyErr, strings.Join(path, " -> "))
+			}
+		}
+		visiting = append(visiting, dependent.Name())
+		for _, dependency := range dependent.Dependencies() {
+			if err := visit(named[dependency]); err != nil {
+				return err
+			}
+		}
+		visiting = visiting[:len(visiting)-1]
+		visited[dependent.Name()] = true
+		sorted = append(sorted, dependent)
+		return nil
+	}
+
+	for _, service := range services {
+		if dependent, ok := service.(Dependent); ok {
+			if err := visit(dependent); err != nil {
+				return nil, err
+			}
+		} else {
+			sorted = append(sorted, service)
+		}
+	}
+
+	return sorted, nil
+}
@@ -0,0 +1,112 @@
+package providers
+
+import (
+	"errors"
+	"github.com/goal-web/contracts"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// plain 没有声明名称和依赖的服务提供者
+type plain struct {
+	label string
+}
+
+func (provider *plain) Register(contracts.Application) {}
+
+func (provider *plain) Start() error { return nil }
+
+func (provider *plain) Stop() {}
+
+func service(name string, dependencies ...string) contracts.ServiceProvider {
+	return Declare(name, &plain{}, dependencies...)
+}
+
+func names(services []contracts.ServiceProvider) []string {
+	var results = make([]string, 0, len(services))
+	for _, item := range services {
+		if dependent, ok := item.(Dependent); ok {
+			results = append(results, dependent.Name())
+		} else {
+			results = append(results, item.(*plain).label)
+		}
+	}
+	return results
+}
+
+func TestSort(t *testing.T) {
+	cases := []struct {
+		name     string
+		services []contracts.ServiceProvider
+		expected []string
+		err      error
+		message  string
+	}{
+		{
+			name:     "keeps the order without dependencies",
+			services: []contracts.ServiceProvider{service("a"), service("b"), service("c")},
+			expected: []string{"a", "b", "c"},
+		},
+		{
+			name:     "dependencies first",
+			services: []contracts.ServiceProvider{service("queue", "database", "config"), service("database", "config"), service("config")},
+			expected: []string{"config", "database", "queue"},
+		},
+		{
+			name:     "shared dependency only once",
+			services: []contracts.ServiceProvider{service("http", "session", "auth"), service("auth", "session"), service("session", "config"), service("config")},
+			expected: []string{"config", "session", "auth", "http"},
+		},
+		{
+			name:     "plain providers stay in place",
+			services: []contracts.ServiceProvider{&plain{label: "exceptions"}, service("b", "a"), &plain{label: "routes"}, service("a")},
+			expected: []string{"exceptions", "a", "b", "routes"},
+		},
+		{
+			name:     "missing dependency",
+			services: []contracts.ServiceProvider{service("queue", "database"), service("mail", "queue", "views")},
+			err:      MissingDependencyErr,
+			message:  "queue 依赖 database，mail 依赖 views",
+		},
+		{
+			name:     "duplicate name",
+			services: []contracts.ServiceProvider{service("config"), service("config")},
+			err:      DuplicateProviderErr,
+			message:  "config",
+		},
+		{
+			name:     "self dependency",
+			services: []contracts.ServiceProvider{service("a", "a")},
+			err:      CircularDependencyErr,
+			message:  "a -> a",
+		},
+		{
+			name:     "cycle",
+			services: []contracts.ServiceProvider{service("config"), service("a", "b"), service("b", "c", "config"), service("c", "a")},
+			err:      CircularDependencyErr,
+			message:  "a -> b -> c -> a",
+		},
+	}
+
+	for _, item := range cases {
+		t.Run(item.name, func(t *testing.T) {
+			sorted, err := Sort(item.services)
+			if item.err != nil {
+				if !errors.Is(err, item.err) {
+					t.Fatalf("err = %v, want %v", err, item.err)
+				}
+				if !strings.Contains(err.Error(), item.message) {
+					t.Errorf("err = %v, want it to contain %q", err, item.message)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual := names(sorted); !reflect.DeepEqual(actual, item.expected) {
+				t.Errorf("sorted = %v, want %v", actual, item.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package providers
+
+import (
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/goal/app/keyring"
+	config2 "github.com/goal-web/goal/config"
+	"github.com/goal-web/supports/exceptions"
+)
+
+type EncryptionServiceProvider struct {
+}
+
+// NewEncryption 加密服务，支持通过 app.previous_keys 轮换应用密钥
+func NewEncryption() Dependent {
+	return &EncryptionServiceProvider{}
+}
+
+func (provider *EncryptionServiceProvider) Name() string {
+	return "encryption"
+}
+
+func (provider *EncryptionServiceProvider) Dependencies() []string {
+	return []string{"config"}
+}
+
+func (provider *EncryptionServiceProvider) Register(app contracts.Application) {
+	app.Singleton("keyring", func(config contracts.Config) *keyring.Keyring {
+		keyringConfig := config.Get("encryption").(config2.EncryptionConfig)
+		instance, err := keyring.New(keyringConfig.Key, keyringConfig.PreviousKeys...)
+		if err != nil {
+			panic(exceptions.WithError(err))
+		}
+		return instance
+	})
+	app.Singleton("encryption", func(instance *keyring.Keyring) contracts.EncryptorFactory {
+		return keyring.NewFactory(instance)
+	})
+	app.Singleton("encryption.default", func(factory contracts.EncryptorFactory) contracts.Encryptor {
+		return factory.Driver("default")
+	})
+}
+
+func (provider *EncryptionServiceProvider) Start() error {
+	return nil
+}
+
+func (provider *EncryptionServiceProvider) Stop() {
+}
@@ -3,6 +3,7 @@ package providers
 import (
 	"github.com/goal-web/contracts"
 	events2 "github.com/goal-web/database/events"
+	"github.com/goal-web/goal/app/events"
 	"github.com/goal-web/goal/app/listeners"
 )
 
@@ -14,10 +15,19 @@ func NewEvents() contracts.ServiceProvider {
 	return &EventsServiceProvider{
 		listeners: map[contracts.Event][]contracts.EventListener{
 			&events2.QueryExecuted{}: {listeners.DebugQuery{}},
+			&events.ConfigChanged{}:   {listeners.ApplyConfig{}},
 		},
 	}
 }
 
+func (provider EventsServiceProvider) Name() string {
+	return "listeners"
+}
+
+func (provider EventsServiceProvider) Dependencies() []string {
+	return []string{"events"}
+}
+
 func (provider EventsServiceProvider) Stop() {
 
 }
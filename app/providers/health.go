@@ -0,0 +1,162 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"github.com/goal-web/contracts"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	HealthUp       = "up"
+	HealthDown     = "down"
+	HealthOk       = "ok"
+	HealthDegraded = "degraded"
+	HealthDraining = "draining"
+)
+
+// checkTimeout 单项检查的最长耗时，超时视为不可用
+const checkTimeout = 3 * time.Second
+
+// Check 健康检查，返回错误表示不可用
+type Check func(ctx context.Context) error
+
+// HealthChecker 提供健康检查的服务提供者，key 为检查项名称
+type HealthChecker interface {
+	HealthChecks(app contracts.Application) map[string]Check
+}
+
+type checked struct {
+	Dependent
+	checks func(app contracts.Application) map[string]Check
+}
+
+// Checked 为没有实现 HealthChecker 的服务提供者（例如框架自带的服务）声明健康检查
+func Checked(provider Dependent, checks func(app contracts.Application) map[string]Check) Dependent {
+	return &checked{Dependent: provider, checks: checks}
+}
+
+func (provider *checked) HealthChecks(app contracts.Application) map[string]Check {
+	return provider.checks(app)
+}
+
+// CheckResult 单项检查结果
+type CheckResult struct {
+	Name    string  `json:"name"`
+	Status  string  `json:"status"`
+	Latency float64 `json:"latency_ms"`
+	Error   string  `json:"error,omitempty"`
+}
+
+// HealthReport 健康检查报告
+type HealthReport struct {
+	Status string        `json:"status"`
+	Checks []CheckResult `json:"checks"`
+}
+
+// Healthy 所有检查项均可用
+func (report HealthReport) Healthy() bool {
+	return report.Status == HealthOk
+}
+
+// Health 汇总所有服务提供者的健康检查
+type Health struct {
+	app      contracts.Application
+	checkers []HealthChecker
+}
+
+// NewHealth 从已注册的服务提供者中收集实现了 HealthChecker 的服务
+func NewHealth(app contracts.Application, services []contracts.ServiceProvider) *Health {
+	var checkers = make([]HealthChecker, 0)
+	for _, service := range services {
+		if checker, ok := service.(HealthChecker); ok {
+			checkers = append(checkers, checker)
+		}
+	}
+	return &Health{app: app, checkers: checkers}
+}
+
+// Check 并发执行所有检查项，有检查项不可用时状态为 degraded
+func (health *Health) Check(ctx context.Context) HealthReport {
+	var checks = make(map[string]Check)
+	for _, checker := range health.checkers {
+		for name, check := range checker.HealthChecks(health.app) {
+			checks[name] = check
+		}
+	}
+
+	var (
+		report = HealthReport{Status: HealthOk, Checks: make([]CheckResult, 0, len(checks))}
+		mutex  sync.Mutex
+		wg     sync.WaitGroup
+	)
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check Check) {
+			defer wg.Done()
+			result := run(ctx, name, check)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			report.Checks = append(report.Checks, result)
+			if result.Status == HealthDown {
+				report.Status = HealthDegraded
+			}
+		}(name, check)
+	}
+	wg.Wait()
+
+	sort.Slice(report.Checks, func(i, j int) bool {
+		return report.Checks[i].Name < report.Checks[j].Name
+	})
+
+	return report
+}
+
+// Ready 在 Check 的基础上，关闭排空期间状态为 draining
+func (health *Health) Ready(ctx context.Context) HealthReport {
+	report := health.Check(ctx)
+	if shutdown, ok := health.app.Get("shutdown").(*ShutdownServiceProvider); ok && shutdown.Draining() {
+		report.Status = HealthDraining
+	}
+	return report
+}
+
+// run 执行单项检查并记录耗时，检查超时或者 panic 都视为不可用
+func run(ctx context.Context, name string, check Check) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	var (
+		startedAt = time.Now()
+		done      = make(chan error, 1)
+		err       error
+	)
+	go func() {
+		defer func() {
+			if value := recover(); value != nil {
+				done <- fmt.Errorf("%v", value)
+			}
+		}()
+		done <- check(ctx)
+	}()
+
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result := CheckResult{
+		Name:    name,
+		Status:  HealthUp,
+		Latency: float64(time.Since(startedAt).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = HealthDown
+		result.Error = err.Error()
+	}
+	return result
+}
@@ -0,0 +1,152 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"github.com/goal-web/application"
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/database"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+// databaseConfig 只提供数据库配置
+type databaseConfig struct {
+	contracts.Config
+	database database.Config
+}
+
+func (config databaseConfig) Get(string) any {
+	return config.database
+}
+
+func checker(checks map[string]Check) contracts.ServiceProvider {
+	return Checked(Declare("checked", &plain{}), func(contracts.Application) map[string]Check {
+		return checks
+	})
+}
+
+func statuses(report HealthReport) map[string]string {
+	var results = make(map[string]string)
+	for _, result := range report.Checks {
+		results[result.Name] = result.Status
+	}
+	return results
+}
+
+func TestHealthCheck(t *testing.T) {
+	var (
+		up      = func(context.Context) error { return nil }
+		down    = func(context.Context) error { return errors.New("down") }
+		panics  = func(context.Context) error { panic("boom") }
+		blocked = func(ctx context.Context) error {
+			<-ctx.Done()
+			time.Sleep(time.Second) // 不理会取消的检查也不能拖住报告
+			return nil
+		}
+	)
+
+	cases := []struct {
+		name     string
+		services []contracts.ServiceProvider
+		status   string
+		expected map[string]string
+	}{
+		{
+			name:     "no checks",
+			services: []contracts.ServiceProvider{service("plain")},
+			status:   HealthOk,
+			expected: map[string]string{},
+		},
+		{
+			name:     "all up",
+			services: []contracts.ServiceProvider{checker(map[string]Check{"a": up}), service("plain"), checker(map[string]Check{"b": up})},
+			status:   HealthOk,
+			expected: map[string]string{"a": HealthUp, "b": HealthUp},
+		},
+		{
+			name:     "errors, panics and timeouts are down",
+			services: []contracts.ServiceProvider{checker(map[string]Check{"a": up, "b": down, "c": panics, "d": blocked})},
+			status:   HealthDegraded,
+			expected: map[string]string{"a": HealthUp, "b": HealthDown, "c": HealthDown, "d": HealthDown},
+		},
+	}
+	for _, item := range cases {
+		t.Run(item.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			var (
+				startedAt = time.Now()
+				report    = NewHealth(application.New(), item.services).Check(ctx)
+			)
+			if elapsed := time.Since(startedAt); elapsed > 500*time.Millisecond {
+				t.Errorf("check took %v", elapsed)
+			}
+			if report.Status != item.status || report.Healthy() != (item.status == HealthOk) {
+				t.Errorf("status = %q, want %q", report.Status, item.status)
+			}
+			if actual := statuses(report); !reflect.DeepEqual(actual, item.expected) {
+				t.Errorf("checks = %v, want %v", actual, item.expected)
+			}
+			if !sort.SliceIsSorted(report.Checks, func(i, j int) bool { return report.Checks[i].Name < report.Checks[j].Name }) {
+				t.Errorf("checks are not sorted by name: %v", report.Checks)
+			}
+		})
+	}
+}
+
+func TestHealthReady(t *testing.T) {
+	var (
+		app      = application.New()
+		shutdown = NewShutdown().(*ShutdownServiceProvider)
+		services = []contracts.ServiceProvider{checker(map[string]Check{"a": func(context.Context) error { return nil }})}
+	)
+	if report := NewHealth(app, services).Ready(context.Background()); report.Status != HealthOk {
+		t.Errorf("status without shutdown provider = %q, want %q", report.Status, HealthOk)
+	}
+
+	shutdown.Register(app)
+	var health = NewHealth(app, append(services, shutdown))
+	if report := health.Ready(context.Background()); report.Status != HealthOk {
+		t.Errorf("status = %q, want %q", report.Status, HealthOk)
+	}
+	shutdown.draining.Store(true)
+	if report := health.Ready(context.Background()); report.Status != HealthDraining || report.Healthy() {
+		t.Errorf("status while draining = %q, want %q", report.Status, HealthDraining)
+	}
+	if report := health.Check(context.Background()); report.Status != HealthOk {
+		t.Errorf("liveness while draining = %q, want %q", report.Status, HealthOk)
+	}
+}
+
+func TestDatabaseChecks(t *testing.T) {
+	var (
+		dir      = t.TempDir()
+		existing = filepath.Join(dir, "existing.db")
+		app      = application.New()
+	)
+	if err := os.WriteFile(existing, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	app.Singleton("config", func() contracts.Config {
+		return databaseConfig{database: database.Config{
+			Default: "sqlite",
+			Connections: map[string]contracts.Fields{
+				"sqlite":  {"driver": "sqlite", "database": existing},
+				"missing": {"driver": "sqlite", "database": filepath.Join(dir, "missing.db")},
+				"mysql":   {"driver": "mysql", "host": ""},
+			},
+		}}
+	})
+
+	var report = NewHealth(app, []contracts.ServiceProvider{Checked(Declare("database", &plain{}), DatabaseChecks)}).Check(context.Background())
+	expected := map[string]string{"database.sqlite": HealthUp, "database.missing": HealthDown}
+	if actual := statuses(report); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("checks = %v, want %v, unconfigured connections are skipped", actual, expected)
+	}
+}
@@ -0,0 +1,81 @@
+package providers
+
+import (
+	"context"
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/http"
+	"sync"
+)
+
+type HttpServiceProvider struct {
+	*http.ServiceProvider
+	app      contracts.Application
+	mutex    sync.Mutex
+	requests sync.WaitGroup
+	draining bool
+}
+
+// NewHttp http 服务，关闭时拒绝新请求并等待进行中的请求完成
+func NewHttp(routes ...any) Dependent {
+	return &HttpServiceProvider{
+		ServiceProvider: http.NewService(routes...).(*http.ServiceProvider),
+	}
+}
+
+func (provider *HttpServiceProvider) Name() string {
+	return "http"
+}
+
+func (provider *HttpServiceProvider) Dependencies() []string {
+	return []string{"config", "events"}
+}
+
+func (provider *HttpServiceProvider) Register(app contracts.Application) {
+	provider.app = app
+	provider.ServiceProvider.Register(app)
+}
+
+func (provider *HttpServiceProvider) Start() error {
+	provider.app.Call(func(router contracts.Router) {
+		router.Use(provider.track)
+	})
+	onDrain(provider.app, DrainRequests, provider.drain)
+
+	return provider.ServiceProvider.Start()
+}
+
+// probes 健康检查路由，关闭期间仍然响应，由 /readyz 自己报告 draining
+var probes = map[string]bool{"/healthz": true, "/readyz": true}
+
+// track 记录进行中的请求，关闭期间直接返回 503
+// websocket、sse 这类长连接不计入，它们在 DrainConnections 阶段关闭
+func (provider *HttpServiceProvider) track(request contracts.HttpRequest, next contracts.Pipe) any {
+	provider.mutex.Lock()
+	if provider.draining && !probes[request.Request().URL.Path] {
+		provider.mutex.Unlock()
+		return http.JsonResponse(contracts.Fields{"error": "server is shutting down"}, 503)
+	}
+	if request.IsWebSocket() || request.Request().Header.Get("Accept") == "text/event-stream" {
+		provider.mutex.Unlock()
+		return next(request)
+	}
+	provider.requests.Add(1)
+	provider.mutex.Unlock()
+
+	defer provider.requests.Done()
+	return next(request)
+}
+
+func (provider *HttpServiceProvider) drain(ctx context.Context) error {
+	provider.mutex.Lock()
+	provider.draining = true
+	provider.mutex.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		provider.requests.Wait()
+		close(done)
+	}()
+
+	return wait(ctx, done, "http requests")
+}
@@ -1,11 +1,13 @@
 package providers
 
 import (
+	"context"
 	"github.com/goal-web/contracts"
 	"github.com/goal-web/goal/app/services"
 	"github.com/goal-web/micro"
 	"github.com/goal-web/microdemo"
 	micro2 "go-micro.dev/v4"
+	"go-micro.dev/v4/registry"
 )
 
 type MicroServiceProvider struct {
@@ -13,10 +15,20 @@ type MicroServiceProvider struct {
 	withServer bool
 }
 
+// NewMicro 不启动服务端时只作为客户端使用，延迟到第一次用到时再加载
 func NewMicro(withServer bool) contracts.ServiceProvider {
-	return &MicroServiceProvider{micro.ServiceProvider{
+	provider := &MicroServiceProvider{micro.ServiceProvider{
 		ServiceRegister: register,
 	}, withServer}
+
+	if withServer {
+		return provider
+	}
+
+	return Defer(provider,
+		Provides[micro2.Service]("micro"),
+		Provides[microdemo.HelloService]("hello"),
+	)
 }
 
 // register 返回错误将阻止 app 启动
@@ -24,6 +36,14 @@ func register(service micro2.Service) error {
 	return microdemo.RegisterHelloServiceHandler(service.Server(), new(services.HelloService))
 }
 
+func (provider *MicroServiceProvider) Name() string {
+	return "micro"
+}
+
+func (provider *MicroServiceProvider) Dependencies() []string {
+	return []string{"config"}
+}
+
 func (provider *MicroServiceProvider) Register(app contracts.Application) {
 	provider.ServiceProvider.Register(app)
 
@@ -43,3 +63,13 @@ func (provider *MicroServiceProvider) Stop() {
 		provider.ServiceProvider.Stop()
 	}
 }
+
+// HealthChecks 检查服务注册中心能否访问
+func (provider *MicroServiceProvider) HealthChecks(app contracts.Application) map[string]Check {
+	return map[string]Check{
+		"micro.registry": func(ctx context.Context) error {
+			_, err := app.Get("micro").(micro2.Service).Options().Registry.ListServices(registry.ListContext(ctx))
+			return err
+		},
+	}
+}
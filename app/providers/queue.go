@@ -0,0 +1,236 @@
+package providers
+
+import (
+	"context"
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/database"
+	"github.com/goal-web/goal/app/dbcheck"
+	"github.com/goal-web/goal/app/locks"
+	appqueue "github.com/goal-web/goal/app/queue"
+	config2 "github.com/goal-web/goal/config"
+	"github.com/goal-web/queue"
+	"github.com/goal-web/supports/exceptions"
+	"github.com/goal-web/supports/logs"
+	"github.com/goal-web/supports/utils"
+	"sync"
+	"sync/atomic"
+)
+
+type QueueServiceProvider struct {
+	*queue.ServiceProvider
+	app         contracts.Application
+	withWorkers bool
+	workers     []*appqueue.Worker
+	mutex       sync.Mutex
+	stopping    atomic.Bool
+	closed      chan struct{}
+	once        sync.Once
+}
+
+// NewQueue 队列服务，关闭时先停止消费并在宽限期内等待进行中的任务完成
+// 宽限期内没有完成的任务不会被确认，由队列重新投递
+// worker 由 queue:work 的选项决定，没有通过 queue:work 启动时按 config/queue.go 中当前环境的 Workers 启动
+func NewQueue(withWorkers bool) Dependent {
+	return &QueueServiceProvider{
+		ServiceProvider: queue.NewService(false).(*queue.ServiceProvider),
+		withWorkers:     withWorkers,
+		closed:          make(chan struct{}),
+	}
+}
+
+func (provider *QueueServiceProvider) Name() string {
+	return "queue"
+}
+
+func (provider *QueueServiceProvider) Dependencies() []string {
+	return []string{"config", "serialization", "database"}
+}
+
+func (provider *QueueServiceProvider) Register(app contracts.Application) {
+	provider.app = app
+	provider.ServiceProvider.Register(&queueRegistrar{Application: app})
+	app.Singleton("queue.failed", func(config contracts.Config, db contracts.DBFactory, factory contracts.QueueFactory, serializer contracts.JobSerializer) *appqueue.FailedJobs {
+		failed := config.Get("queue").(queue.Config).Failed
+		return appqueue.NewFailedJobs(db.Connection(failed.Database), failed.Table, factory, serializer)
+	})
+	app.Singleton("queue.locks", func(config contracts.Config) locks.Store {
+		conf := config.Get("queue_locks").(config2.LocksConfig)
+		store, err := locks.Resolve(app, conf.Store, conf.Connection, conf.Prefix)
+		if err != nil {
+			panic(exceptions.WithError(err))
+		}
+		return store
+	})
+}
+
+func (provider *QueueServiceProvider) Start() error {
+	if !provider.withWorkers {
+		return provider.ServiceProvider.Start()
+	}
+	onDrain(provider.app, DrainJobs, provider.drain)
+
+	var (
+		finished sync.WaitGroup
+		errs     = make(chan error, 1)
+		invalid  error
+	)
+	provider.app.Call(func(config contracts.Config, factory contracts.QueueFactory, failed *appqueue.FailedJobs, serializer contracts.JobSerializer, handler contracts.ExceptionHandler) {
+		options, exists := provider.app.Get("queue.workers").([]appqueue.WorkerOptions)
+		if !exists {
+			options = appqueue.ConfigWorkers(config.Get("queue").(queue.Config), config.GetString("app.env"))
+		}
+		// 没有可以启动的 worker 时进程会直接以 0 退出，看起来像是正常消费完了
+		if invalid = appqueue.CheckWorkers(options, config.GetString("app.env")); invalid != nil {
+			return
+		}
+
+		provider.mutex.Lock()
+		defer provider.mutex.Unlock()
+		if provider.stopping.Load() {
+			return
+		}
+		for _, item := range options {
+			worker := appqueue.NewWorker(provider.app, factory.Connection(item.Connection), failed, serializer, handler, item)
+			provider.workers = append(provider.workers, worker)
+			finished.Add(1)
+			go func() {
+				defer finished.Done()
+				if err := worker.Work(); err != nil {
+					logs.WithError(err).Error("providers.Queue: worker failed")
+					select {
+					case errs <- err:
+					default:
+					}
+				}
+			}()
+		}
+	})
+	if invalid != nil {
+		shutdown(provider.app)
+		return invalid
+	}
+	var all = make(chan struct{})
+	go func() {
+		finished.Wait()
+		close(all)
+	}()
+	select {
+	case <-all:
+	case <-provider.closed:
+		return nil
+	}
+
+	// 所有 worker 都因为 --once、--max-jobs、--max-time 或者异常退出时关闭整个进程
+	if !provider.stopping.Load() {
+		logs.Default().Info("providers.Queue: all workers finished")
+		shutdown(provider.app)
+	}
+	select {
+	case err := <-errs:
+		return err
+	default:
+		return nil
+	}
+}
+
+// drain 停止取新的任务，等待执行中的任务完成
+func (provider *QueueServiceProvider) drain(ctx context.Context) error {
+	var (
+		workers = provider.stopWorkers()
+		done    = make(chan struct{})
+	)
+	go func() {
+		for _, worker := range workers {
+			worker.Wait()
+		}
+		close(done)
+	}()
+
+	return wait(ctx, done, "queue workers")
+}
+
+// Stop 停止所有 worker，不再等待执行中的任务
+func (provider *QueueServiceProvider) Stop() {
+	provider.stopWorkers()
+	provider.once.Do(func() {
+		close(provider.closed)
+	})
+}
+
+func (provider *QueueServiceProvider) stopWorkers() []*appqueue.Worker {
+	provider.stopping.Store(true)
+	provider.mutex.Lock()
+	workers := provider.workers
+	provider.mutex.Unlock()
+
+	for _, worker := range workers {
+		worker.Stop()
+	}
+	return workers
+}
+
+// HealthChecks 检查默认队列连接能否连通，sync 驱动不需要检查，database、redis 驱动检查对应的数据库和 redis 连接
+func (provider *QueueServiceProvider) HealthChecks(app contracts.Application) map[string]Check {
+	var (
+		config     = app.Get("config").(contracts.Config)
+		queues     = config.Get("queue").(queue.Config)
+		connection = queues.Connections[queues.Defaults.Connection]
+		name       = "queue." + queues.Defaults.Connection
+	)
+	switch utils.GetStringField(connection, "driver") {
+	case "sync":
+		return map[string]Check{}
+	case "redis":
+		var store []string
+		if value := utils.GetStringField(connection, "connection"); value != "" {
+			store = []string{value}
+		}
+		return map[string]Check{
+			name: func(ctx context.Context) error {
+				_, err := app.Get("redis.factory").(contracts.RedisFactory).Connection(store...).CommandWithContext(ctx, "ping")
+				return err
+			},
+		}
+	case "database":
+		var (
+			databases = config.Get("database").(database.Config)
+			db        = utils.StringOr(utils.GetStringField(connection, "connection"), databases.Default)
+		)
+		return map[string]Check{
+			name: func(ctx context.Context) error {
+				return dbcheck.Ping(ctx, databases.Connections[db])
+			},
+		}
+	}
+
+	var addresses = []string{utils.GetStringField(connection, "address")}
+	if brokers, ok := connection["brokers"].([]string); ok {
+		addresses = append(addresses, brokers...)
+	}
+	return map[string]Check{
+		name: func(ctx context.Context) error {
+			return dial(ctx, addresses...)
+		},
+	}
+}
+
+// queueRegistrar 框架的 queue.factory 只能在解析之后通过 Extend 添加驱动
+// 注册框架的队列服务时拦截 queue.factory，在它第一次被解析时加上 sync、database、redis 驱动
+type queueRegistrar struct {
+	contracts.Application
+}
+
+func (registrar *queueRegistrar) Singleton(key string, provider any) {
+	factory, ok := provider.(func(contracts.Config, contracts.JobSerializer) contracts.QueueFactory)
+	if key != "queue.factory" || !ok {
+		registrar.Application.Singleton(key, provider)
+		return
+	}
+	registrar.Application.Singleton(key, func(config contracts.Config, serializer contracts.JobSerializer) contracts.QueueFactory {
+		instance := factory(config, serializer)
+		instance.Extend("sync", appqueue.SyncDriver)
+		instance.Extend("database", appqueue.DatabaseDriver(registrar.Application))
+		instance.Extend("redis", appqueue.RedisDriver(registrar.Application))
+		return instance
+	})
+}
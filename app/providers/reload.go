@@ -0,0 +1,105 @@
+package providers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/goal/app/events"
+	"github.com/goal-web/supports/logs"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+)
+
+// Reloader 重新加载配置，返回变化的配置项，见 config.Reload
+type Reloader func(env contracts.Env, config contracts.Config) ([]string, []error)
+
+type ReloadServiceProvider struct {
+	app           contracts.Application
+	reloader      Reloader
+	signals       []os.Signal
+	signalChannel chan os.Signal
+	stopped       chan struct{}
+	stopOnce      sync.Once
+	mutex         sync.Mutex
+}
+
+// NewReload 收到给定信号后热加载配置，并通过 events.ConfigChanged 通知监听器应用变化
+func NewReload(reloader Reloader, signals ...os.Signal) Dependent {
+	return &ReloadServiceProvider{
+		reloader:      reloader,
+		signals:       signals,
+		signalChannel: make(chan os.Signal, 1),
+		stopped:       make(chan struct{}),
+	}
+}
+
+func (provider *ReloadServiceProvider) Name() string {
+	return "reload"
+}
+
+func (provider *ReloadServiceProvider) Dependencies() []string {
+	return []string{"config", "events", "listeners"}
+}
+
+func (provider *ReloadServiceProvider) Register(app contracts.Application) {
+	provider.app = app
+	app.Singleton("reload", func() *ReloadServiceProvider {
+		return provider
+	})
+}
+
+func (provider *ReloadServiceProvider) Start() error {
+	signal.Notify(provider.signalChannel, provider.signals...)
+	defer signal.Stop(provider.signalChannel)
+	for {
+		select {
+		case sign := <-provider.signalChannel:
+			logs.Default().Info(fmt.Sprintf("providers.Reload: received %s, reloading config", sign))
+			if _, err := provider.Reload(); err != nil {
+				logs.WithError(err).Error("providers.Reload: reload failed, keep using the previous config")
+			}
+		case <-provider.stopped:
+			return nil
+		}
+	}
+}
+
+// Stop 不再热加载，没有 Start 过也可以调用
+func (provider *ReloadServiceProvider) Stop() {
+	provider.stopOnce.Do(func() {
+		signal.Stop(provider.signalChannel)
+		close(provider.stopped)
+	})
+}
+
+// Reload 重新加载配置并触发 events.ConfigChanged，配置校验不通过时返回错误
+func (provider *ReloadServiceProvider) Reload() (*events.ConfigChanged, error) {
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+
+	var (
+		env    = provider.app.Get("env").(contracts.Env)
+		config = provider.app.Get("config").(contracts.Config)
+	)
+	changes, problems := provider.reloader(env, config)
+	if len(problems) > 0 {
+		return nil, errors.Join(problems...)
+	}
+
+	event := events.NewConfigChanged(changes)
+	if len(changes) == 0 {
+		logs.Default().Info("providers.Reload: config unchanged")
+		return event, nil
+	}
+
+	provider.app.Get("events").(contracts.EventDispatcher).Dispatch(event)
+
+	if restart := event.RequiresRestart(); len(restart) > 0 {
+		logs.WithField("changes", restart).Warn("providers.Reload: requires restart: " + strings.Join(restart, ", "))
+	}
+	logs.WithField("changes", changes).Info("providers.Reload: config reloaded")
+
+	return event, nil
+}
@@ -0,0 +1,96 @@
+package providers
+
+import (
+	"context"
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/goal/app/scheduling"
+	"github.com/goal-web/supports/exceptions"
+	"github.com/goal-web/supports/logs"
+	"sync"
+	"time"
+)
+
+type SchedulingServiceProvider struct {
+	app     contracts.Application
+	done    chan struct{}
+	once    sync.Once
+	running sync.WaitGroup
+}
+
+// NewScheduling 任务调度，每秒检查一次 Kernel.Schedule 中到期的任务并发执行
+// 与框架自带的调度不同，到期时间按各任务的时区计算，关闭时会等待执行中的任务完成
+func NewScheduling() Dependent {
+	return &SchedulingServiceProvider{done: make(chan struct{})}
+}
+
+func (provider *SchedulingServiceProvider) Name() string {
+	return "scheduling"
+}
+
+func (provider *SchedulingServiceProvider) Dependencies() []string {
+	return []string{"console"}
+}
+
+func (provider *SchedulingServiceProvider) Register(app contracts.Application) {
+	provider.app = app
+}
+
+func (provider *SchedulingServiceProvider) Start() error {
+	var (
+		schedule = provider.app.Get("scheduling").(*scheduling.Schedule)
+		tasks    = scheduling.Tasks(schedule)
+		handler  = provider.app.Get("exceptions.handler").(contracts.ExceptionHandler)
+		ticker   = time.NewTicker(100 * time.Millisecond)
+		last     = time.Now().Truncate(time.Second)
+	)
+	defer ticker.Stop()
+	onDrain(provider.app, DrainJobs, provider.drain)
+	_, _ = schedule.History() // 执行历史的连接只在启动时解析一次
+
+	for {
+		select {
+		case <-provider.done:
+			logs.Default().Info("providers.Scheduling: closed")
+			return nil
+		case now := <-ticker.C:
+			// 逐秒检查上次检查之后的每一秒，避免定时器抖动时漏掉任务
+			for second := last.Add(time.Second); !second.After(now); second = second.Add(time.Second) {
+				provider.dispatch(tasks, second, handler)
+				last = second
+			}
+		}
+	}
+}
+
+func (provider *SchedulingServiceProvider) dispatch(tasks []scheduling.Task, second time.Time, handler contracts.ExceptionHandler) {
+	for _, task := range tasks {
+		if !task.DueAt(second) {
+			continue
+		}
+		provider.running.Add(1)
+		go func(task scheduling.Task) {
+			defer provider.running.Done()
+			if result := task.Run(provider.app, second); result.Err != nil && !result.Skipped() {
+				handler.Handle(exceptions.WithError(result.Err))
+			}
+		}(task)
+	}
+}
+
+// drain 停止调度新的任务，等待执行中的任务完成
+func (provider *SchedulingServiceProvider) drain(ctx context.Context) error {
+	provider.Stop()
+	done := make(chan struct{})
+	go func() {
+		provider.running.Wait()
+		close(done)
+	}()
+
+	return wait(ctx, done, "scheduled tasks")
+}
+
+func (provider *SchedulingServiceProvider) Stop() {
+	provider.once.Do(func() {
+		close(provider.done)
+	})
+}
@@ -0,0 +1,155 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"github.com/goal-web/contracts"
+	config2 "github.com/goal-web/goal/config"
+	"github.com/goal-web/supports/logs"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 排空阶段，关闭时按顺序执行，同一阶段的排空操作并发执行
+const (
+	DrainRequests    = iota // 停止接收新请求，等待进行中的请求完成
+	DrainJobs               // 停止消费队列，等待进行中的任务完成
+	DrainConnections        // 向 websocket、sse 客户端发送关闭帧
+	drainStages
+)
+
+// Drainer 排空操作，ctx 到期后应该放弃等待并返回错误
+type Drainer func(ctx context.Context) error
+
+type ShutdownServiceProvider struct {
+	app           contracts.Application
+	signals       []os.Signal
+	signalChannel chan os.Signal
+	stopped       chan struct{}
+	stopOnce      sync.Once
+	drainers      [drainStages][]Drainer
+	mutex         sync.Mutex
+	draining      atomic.Bool
+	once          sync.Once
+}
+
+// NewShutdown 收到给定信号后按阶段排空各服务，然后倒序关闭所有服务
+func NewShutdown(signals ...os.Signal) Dependent {
+	return &ShutdownServiceProvider{
+		signals:       signals,
+		signalChannel: make(chan os.Signal, 1),
+		stopped:       make(chan struct{}),
+	}
+}
+
+func (provider *ShutdownServiceProvider) Name() string {
+	return "shutdown"
+}
+
+func (provider *ShutdownServiceProvider) Dependencies() []string {
+	return []string{"config"}
+}
+
+func (provider *ShutdownServiceProvider) Register(app contracts.Application) {
+	provider.app = app
+	app.Singleton("shutdown", func() *ShutdownServiceProvider {
+		return provider
+	})
+}
+
+func (provider *ShutdownServiceProvider) Start() error {
+	signal.Notify(provider.signalChannel, provider.signals...)
+	defer signal.Stop(provider.signalChannel)
+	for {
+		select {
+		case sign := <-provider.signalChannel:
+			logs.Default().Info(fmt.Sprintf("providers.Shutdown: received %s, shutting down", sign))
+			go provider.Shutdown()
+		case <-provider.stopped:
+			return nil
+		}
+	}
+}
+
+// Stop 停止监听信号，可以在 Start 之前调用，也可以重复调用
+func (provider *ShutdownServiceProvider) Stop() {
+	provider.stopOnce.Do(func() {
+		signal.Stop(provider.signalChannel)
+		close(provider.stopped)
+	})
+}
+
+// OnDrain 注册给定阶段的排空操作
+func (provider *ShutdownServiceProvider) OnDrain(stage int, drainer Drainer) {
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+	provider.drainers[stage] = append(provider.drainers[stage], drainer)
+}
+
+// Draining 是否正在关闭
+func (provider *ShutdownServiceProvider) Draining() bool {
+	return provider.draining.Load()
+}
+
+// Shutdown 在宽限期内按阶段排空各服务，然后倒序关闭所有服务
+func (provider *ShutdownServiceProvider) Shutdown() {
+	provider.once.Do(func() {
+		provider.draining.Store(true)
+
+		var timeout = 30 * time.Second
+		provider.app.Call(func(config contracts.Config) {
+			if shutdownConfig, ok := config.Get("shutdown").(config2.ShutdownConfig); ok && shutdownConfig.Timeout > 0 {
+				timeout = shutdownConfig.Timeout
+			}
+		})
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		provider.mutex.Lock()
+		stages := provider.drainers
+		provider.mutex.Unlock()
+
+		for _, drainers := range stages {
+			var wg sync.WaitGroup
+			for _, drainer := range drainers {
+				wg.Add(1)
+				go func(drainer Drainer) {
+					defer wg.Done()
+					if err := drainer(ctx); err != nil {
+						logs.WithError(err).Warn("providers.Shutdown: drain failed")
+					}
+				}(drainer)
+			}
+			wg.Wait()
+		}
+
+		provider.app.Stop()
+	})
+}
+
+// onDrain 注册排空操作，没有注册关闭服务时（例如控制台命令）忽略
+func onDrain(app contracts.Application, stage int, drainer Drainer) {
+	if shutdown, ok := app.Get("shutdown").(*ShutdownServiceProvider); ok {
+		shutdown.OnDrain(stage, drainer)
+	}
+}
+
+// shutdown 主动关闭进程，例如 worker 达到退出条件时，没有注册关闭服务时忽略
+func shutdown(app contracts.Application) {
+	if provider, ok := app.Get("shutdown").(*ShutdownServiceProvider); ok {
+		go provider.Shutdown()
+	}
+}
+
+// wait 等待 done 关闭，ctx 到期时返回错误
+func wait(ctx context.Context, done <-chan struct{}, name string) error {
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%s: %w", name, ctx.Err())
+	}
+}
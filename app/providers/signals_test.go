@@ -0,0 +1,66 @@
+package providers
+
+import (
+	"github.com/goal-web/contracts"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestSignalProvidersStop(t *testing.T) {
+	var reloader = func(contracts.Env, contracts.Config) ([]string, []error) { return nil, nil }
+
+	cases := []struct {
+		name     string
+		provider func() contracts.ServiceProvider
+	}{
+		{"shutdown", func() contracts.ServiceProvider { return NewShutdown(syscall.SIGUSR2) }},
+		{"reload", func() contracts.ServiceProvider { return NewReload(reloader, syscall.SIGUSR2) }},
+	}
+	for _, item := range cases {
+		t.Run(item.name+" stopped before start", func(t *testing.T) {
+			provider := item.provider()
+			provider.Stop()
+			provider.Stop()
+			if err := start(t, provider); err != nil {
+				t.Fatal(err)
+			}
+		})
+		t.Run(item.name+" stopped after start", func(t *testing.T) {
+			var (
+				provider = item.provider()
+				done     = make(chan error, 1)
+			)
+			go func() {
+				done <- provider.Start()
+			}()
+			time.Sleep(10 * time.Millisecond)
+			provider.Stop()
+			provider.Stop()
+			select {
+			case err := <-done:
+				if err != nil {
+					t.Fatal(err)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("Start did not return after Stop")
+			}
+		})
+	}
+}
+
+// start 调用 Start，一秒内没有返回时测试失败
+func start(t *testing.T, provider contracts.ServiceProvider) error {
+	t.Helper()
+	var done = make(chan error, 1)
+	go func() {
+		done <- provider.Start()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(time.Second):
+		t.Fatal("Start did not return after Stop")
+		return nil
+	}
+}
@@ -0,0 +1,32 @@
+package providers
+
+import (
+	"context"
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/http/sse"
+)
+
+type SseServiceProvider struct {
+	Dependent
+	app contracts.Application
+}
+
+// NewSse sse 服务，延迟加载，关闭时结束所有事件流
+func NewSse() Dependent {
+	return Defer(&SseServiceProvider{
+		Dependent: Declare("sse", sse.NewService(), "http"),
+	}, Provides[contracts.Sse]("sse"))
+}
+
+func (provider *SseServiceProvider) Register(app contracts.Application) {
+	provider.app = app
+	provider.Dependent.Register(app)
+}
+
+func (provider *SseServiceProvider) Start() error {
+	onDrain(provider.app, DrainConnections, func(ctx context.Context) error {
+		closeConnections(provider.app.Get("sse").(contracts.Sse))
+		return nil
+	})
+	return provider.Dependent.Start()
+}
@@ -0,0 +1,46 @@
+package providers
+
+import (
+	"context"
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/websocket"
+)
+
+type WebSocketServiceProvider struct {
+	Dependent
+	app contracts.Application
+}
+
+// NewWebSocket websocket 服务，延迟加载，关闭时关闭所有连接
+// 连接由 app/websocket 创建，关闭时会向客户端发送关闭帧
+func NewWebSocket() Dependent {
+	return Defer(&WebSocketServiceProvider{
+		Dependent: Declare("websocket", websocket.NewService(), "config", "http"),
+	}, Provides[contracts.WebSocket]("websocket"))
+}
+
+func (provider *WebSocketServiceProvider) Register(app contracts.Application) {
+	provider.app = app
+	provider.Dependent.Register(app)
+}
+
+func (provider *WebSocketServiceProvider) Start() error {
+	onDrain(provider.app, DrainConnections, func(ctx context.Context) error {
+		closeConnections(provider.app.Get("websocket").(contracts.WebSocket))
+		return nil
+	})
+	return provider.Dependent.Start()
+}
+
+type connections interface {
+	GetFd() uint64
+	Close(fd uint64) error
+}
+
+// closeConnections 关闭所有连接，fd 从 1 开始自增，GetFd 会占用一个新的 fd，所以关闭它之前的全部连接
+func closeConnections(connections connections) {
+	last := connections.GetFd()
+	for fd := uint64(1); fd < last; fd++ {
+		_ = connections.Close(fd)
+	}
+}
@@ -0,0 +1,170 @@
+package queue
+
+import (
+	"fmt"
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/supports/logs"
+	"github.com/goal-web/supports/utils"
+	"sync"
+	"time"
+)
+
+// Database 以数据表作为队列，表结构见 database/migrations 中的 jobs 表
+// 取出任务时把 reserved_at 设为当前时间，确认后删除；超过 retry_after 秒仍未确认的任务（例如 worker 中途退出）会被重新取出
+type Database struct {
+	name         string
+	db           contracts.DBConnection
+	table        string
+	defaultQueue string
+	retryAfter   int64
+	serializer   contracts.JobSerializer
+
+	stop chan struct{}
+	once sync.Once
+}
+
+// DatabaseDriver 配置中的 connection 为 config/database.go 中的连接，table 默认为 jobs，retry_after 默认为 90 秒
+func DatabaseDriver(app contracts.Application) contracts.QueueDriver {
+	return func(name string, config contracts.Fields, serializer contracts.JobSerializer) contracts.Queue {
+		return &Database{
+			name:         name,
+			db:           app.Get("db.factory").(contracts.DBFactory).Connection(utils.GetStringField(config, "connection")),
+			table:        utils.GetStringField(config, "table", "jobs"),
+			defaultQueue: utils.GetStringField(config, "default", "default"),
+			retryAfter:   utils.GetInt64Field(config, "retry_after", 90),
+			serializer:   serializer,
+			stop:         make(chan struct{}),
+		}
+	}
+}
+
+func (database *Database) Push(job contracts.Job, queue ...string) error {
+	return database.Later(time.Now(), job, queue...)
+}
+
+func (database *Database) PushOn(queue string, job contracts.Job) error {
+	return database.LaterOn(queue, time.Now(), job)
+}
+
+func (database *Database) PushRaw(payload, queue string, _ ...contracts.Fields) error {
+	return database.insert(queue, payload, 0, time.Now())
+}
+
+func (database *Database) Later(delay time.Time, job contracts.Job, queue ...string) error {
+	if len(queue) > 0 && queue[0] != "" {
+		return database.LaterOn(queue[0], delay, job)
+	}
+	if job.GetQueue() != "" {
+		return database.LaterOn(job.GetQueue(), delay, job)
+	}
+	return database.LaterOn(database.defaultQueue, delay, job)
+}
+
+// LaterOn 已经尝试过的次数记录在 attempts 中，worker 中途退出导致的重新取出也会计入尝试次数
+func (database *Database) LaterOn(queue string, delay time.Time, job contracts.Job) error {
+	job.SetQueue(queue)
+	return database.insert(queue, database.serializer.Serializer(job), job.GetAttemptsNum(), delay)
+}
+
+func (database *Database) GetConnectionName() string {
+	return database.name
+}
+
+func (database *Database) Release(job contracts.Job, delay ...int) error {
+	var at = time.Now()
+	if len(delay) > 0 {
+		at = at.Add(time.Duration(delay[0]) * time.Second)
+	}
+	return database.Later(at, job)
+}
+
+type databaseRecord struct {
+	ID         int64  `db:"id"`
+	Payload    string `db:"payload"`
+	Attempts   int    `db:"attempts"`
+	ReservedAt *int64 `db:"reserved_at"`
+}
+
+// Pop 取出给定队列中最早可用的任务，多个 worker 同时取同一条时只有一个能更新成功
+func (database *Database) Pop(queue string) (*contracts.Msg, error) {
+	for {
+		var (
+			now     = time.Now().Unix()
+			records = make([]databaseRecord, 0)
+		)
+		if err := database.db.Select(&records, fmt.Sprintf(
+			"select id, payload, attempts, reserved_at from %s where queue = ? and ((reserved_at is null and available_at <= ?) or reserved_at <= ?) order by id limit 1",
+			database.table,
+		), queue, now, now-database.retryAfter); err != nil {
+			return nil, err
+		}
+		if len(records) == 0 {
+			return nil, nil
+		}
+
+		record := records[0]
+		reserved, err := database.reserve(record, now)
+		if err != nil {
+			return nil, err
+		}
+		if !reserved {
+			continue
+		}
+
+		job, err := database.serializer.Unserialize(record.Payload)
+		if err != nil {
+			return nil, fmt.Errorf("%s：%d：%w", database.table, record.ID, err)
+		}
+		if base := baseJob(job); base != nil {
+			base.Tries = record.Attempts
+		}
+		return &contracts.Msg{
+			Job: job,
+			Ack: func() {
+				if _, err := database.db.Exec(fmt.Sprintf("delete from %s where id = ?", database.table), record.ID); err != nil {
+					logs.WithError(err).WithField("id", record.ID).Error("queue.Database: ack failed")
+				}
+			},
+		}, nil
+	}
+}
+
+// reserve 只有 reserved_at 仍然是查询时的值才更新，返回是否抢到
+func (database *Database) reserve(record databaseRecord, now int64) (bool, error) {
+	var (
+		query = fmt.Sprintf("update %s set reserved_at = ?, attempts = attempts + 1 where id = ? and reserved_at is null", database.table)
+		args  = []any{now, record.ID}
+	)
+	if record.ReservedAt != nil {
+		query = fmt.Sprintf("update %s set reserved_at = ?, attempts = attempts + 1 where id = ? and reserved_at = ?", database.table)
+		args = append(args, *record.ReservedAt)
+	}
+	result, exception := database.db.Exec(query, args...)
+	if exception != nil {
+		return false, exception
+	}
+	affected, err := result.RowsAffected()
+	return affected == 1, err
+}
+
+// Listen 按给定顺序轮询队列，queue:work 直接使用 Pop
+func (database *Database) Listen(queue ...string) chan contracts.Msg {
+	return listen(database, queue, database.stop)
+}
+
+func (database *Database) Stop() {
+	database.once.Do(func() {
+		close(database.stop)
+	})
+}
+
+func (database *Database) insert(queue, payload string, attempts int, available time.Time) error {
+	_, err := database.db.Exec(
+		fmt.Sprintf("insert into %s (queue, payload, attempts, available_at, created_at) values (?, ?, ?, ?, ?)", database.table),
+		queue, payload, attempts, available.Unix(), time.Now().Unix(),
+	)
+	if err != nil {
+		return err
+	}
+	return nil
+}
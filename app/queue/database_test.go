@@ -0,0 +1,198 @@
+package queue
+
+import (
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/database/drivers"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// newDatabase 使用临时 sqlite 文件，jobs 表与 database/migrations 中的一致
+func newDatabase(t *testing.T, retryAfter int64) *Database {
+	t.Helper()
+	db := drivers.SqliteConnector(contracts.Fields{"database": filepath.Join(t.TempDir(), "test.db")}, nil)
+	if _, err := db.Exec(`create table jobs (
+		id integer primary key autoincrement,
+		queue varchar(255) not null,
+		payload text not null,
+		attempts integer not null default 0,
+		reserved_at bigint null,
+		available_at bigint not null,
+		created_at bigint not null
+	)`); err != nil {
+		t.Fatal(err)
+	}
+	return &Database{
+		name:         "database",
+		db:           db,
+		table:        "jobs",
+		defaultQueue: "default",
+		retryAfter:   retryAfter,
+		serializer:   newSerializer(),
+		stop:         make(chan struct{}),
+	}
+}
+
+// popNames 依次从给定队列中取出任务直到为空，返回任务名称和之前的尝试次数，不确认
+func popNames(t *testing.T, poller Poller, queues ...string) ([]string, []int) {
+	t.Helper()
+	var (
+		names    = make([]string, 0)
+		attempts = make([]int, 0)
+	)
+	for msg := pop(poller, queues); msg != nil; msg = pop(poller, queues) {
+		job := msg.Job.(*sampleJob)
+		names = append(names, job.Name)
+		attempts = append(attempts, job.GetAttemptsNum())
+		if len(names) > 10 {
+			t.Fatal("too many jobs")
+		}
+	}
+	return names, attempts
+}
+
+func count(t *testing.T, database *Database) int {
+	t.Helper()
+	var counts = make([]int, 0)
+	if err := database.db.Select(&counts, "select count(*) from jobs"); err != nil {
+		t.Fatal(err)
+	}
+	return counts[0]
+}
+
+func TestDatabasePop(t *testing.T) {
+	cases := []struct {
+		name     string
+		push     func(database *Database)
+		queues   []string
+		expected []string
+	}{
+		{
+			name: "jobs are popped in order",
+			push: func(database *Database) {
+				_ = database.Push(newSampleJob("a"))
+				_ = database.Push(newSampleJob("b"))
+			},
+			queues:   []string{"default"},
+			expected: []string{"a", "b"},
+		},
+		{
+			name: "queues are popped by priority",
+			push: func(database *Database) {
+				_ = database.Push(newSampleJob("low"))
+				_ = database.PushOn("high", newSampleJob("high"))
+			},
+			queues:   []string{"high", "default"},
+			expected: []string{"high", "low"},
+		},
+		{
+			name: "other queues are ignored",
+			push: func(database *Database) {
+				_ = database.Push(newSampleJob("a"), "slow")
+				_ = database.Push(newSampleJob("b"))
+			},
+			queues:   []string{"default"},
+			expected: []string{"b"},
+		},
+		{
+			name: "delayed jobs are not available yet",
+			push: func(database *Database) {
+				_ = database.Later(time.Now().Add(time.Hour), newSampleJob("later"))
+				_ = database.Release(newSampleJob("released"), 60)
+				_ = database.Later(time.Now().Add(-time.Second), newSampleJob("due"))
+			},
+			queues:   []string{"default"},
+			expected: []string{"due"},
+		},
+		{
+			name: "the queue of the job is used",
+			push: func(database *Database) {
+				job := newSampleJob("a")
+				job.SetQueue("mail")
+				_ = database.Push(job)
+			},
+			queues:   []string{"mail"},
+			expected: []string{"a"},
+		},
+	}
+	for _, item := range cases {
+		t.Run(item.name, func(t *testing.T) {
+			database := newDatabase(t, 90)
+			item.push(database)
+			if names, _ := popNames(t, database, item.queues...); !reflect.DeepEqual(names, item.expected) {
+				t.Errorf("popped %v, want %v", names, item.expected)
+			}
+		})
+	}
+}
+
+func TestDatabaseReservation(t *testing.T) {
+	t.Run("reserved jobs are not popped again and ack deletes them", func(t *testing.T) {
+		database := newDatabase(t, 90)
+		_ = database.Push(newSampleJob("a"))
+
+		msg, err := database.Pop("default")
+		if err != nil || msg == nil {
+			t.Fatalf("Pop = %v, %v", msg, err)
+		}
+		if attempts := msg.Job.GetAttemptsNum(); attempts != 0 {
+			t.Errorf("attempts = %d, want 0", attempts)
+		}
+		if names, _ := popNames(t, database, "default"); len(names) > 0 {
+			t.Errorf("reserved job popped again: %v", names)
+		}
+		msg.Ack()
+		if rows := count(t, database); rows != 0 {
+			t.Errorf("%d jobs left after ack, want 0", rows)
+		}
+	})
+
+	// worker 中途退出的那次也计入尝试次数
+	t.Run("jobs not acked within retry_after are popped again", func(t *testing.T) {
+		database := newDatabase(t, 0)
+		_ = database.Push(newSampleJob("a"))
+
+		if msg, err := database.Pop("default"); err != nil || msg == nil {
+			t.Fatalf("Pop = %v, %v", msg, err)
+		}
+		msg, err := database.Pop("default")
+		if err != nil || msg == nil {
+			t.Fatalf("expired reservation not popped again: %v, %v", msg, err)
+		}
+		if attempts := msg.Job.GetAttemptsNum(); attempts != 1 {
+			t.Errorf("attempts = %d, want 1", attempts)
+		}
+	})
+
+	t.Run("released jobs keep their attempts", func(t *testing.T) {
+		database := newDatabase(t, 90)
+		_ = database.Push(newSampleJob("a"))
+		msg, _ := database.Pop("default")
+		msg.Job.IncrementAttemptsNum() // 与 worker 执行任务前一样
+		msg.Ack()
+		if err := database.Release(msg.Job); err != nil {
+			t.Fatal(err)
+		}
+		if _, attempts := popNames(t, database, "default"); !reflect.DeepEqual(attempts, []int{1}) {
+			t.Errorf("attempts = %v, want [1]", attempts)
+		}
+	})
+}
+
+func TestDatabaseListen(t *testing.T) {
+	database := newDatabase(t, 90)
+	_ = database.Push(newSampleJob("a"))
+
+	select {
+	case msg := <-database.Listen("default"):
+		if name := msg.Job.(*sampleJob).Name; name != "a" {
+			t.Errorf("listened %q, want %q", name, "a")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no job listened")
+	}
+	database.Stop()
+	database.Stop()
+}
@@ -0,0 +1,294 @@
+package queue
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/goal/app/migration"
+	"github.com/goal-web/queue"
+	"github.com/goal-web/serialization"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	FailedJobNotFoundErr = errors.New("失败的任务不存在")
+	RetryFailedErr       = errors.New("重试失败")
+	InvalidIDsErr        = errors.New("任务 id 格式有误")
+)
+
+// FailedJob failed_jobs 表中的一条记录
+type FailedJob struct {
+	ID         int64     `json:"id"`
+	Connection string    `json:"connection"`
+	Queue      string    `json:"queue"`
+	Class      string    `json:"class"`
+	Payload    string    `json:"payload"`
+	Exception  string    `json:"exception"`
+	FailedAt   time.Time `json:"failed_at"`
+}
+
+// Excerpt 异常信息的第一行，最多 length 个字符
+func (job FailedJob) Excerpt(length int) string {
+	line := strings.TrimSpace(job.Exception)
+	if index := strings.IndexByte(line, '\n'); index >= 0 {
+		line = line[:index]
+	}
+	if runes := []rune(line); len(runes) > length {
+		return string(runes[:length]) + "..."
+	}
+	return line
+}
+
+type failedRecord struct {
+	ID         int64  `db:"id"`
+	Connection string `db:"connection"`
+	Queue      string `db:"queue"`
+	Payload    string `db:"payload"`
+	Exception  string `db:"exception"`
+	FailedAt   string `db:"failed_at"`
+}
+
+// FailedJobs 管理 config/queue.go 中 Failed 指定的死信表
+type FailedJobs struct {
+	db         contracts.DBConnection
+	table      string
+	queues     contracts.QueueFactory
+	serializer contracts.JobSerializer
+}
+
+func NewFailedJobs(db contracts.DBConnection, table string, queues contracts.QueueFactory, serializer contracts.JobSerializer) *FailedJobs {
+	return &FailedJobs{db: db, table: table, queues: queues, serializer: serializer}
+}
+
+// Log 记录一个失败的任务
+func (failed *FailedJobs) Log(connection, queue, payload, exception string) error {
+	_, err := failed.db.Exec(
+		fmt.Sprintf("insert into %s (connection, queue, payload, exception, failed_at) values (?, ?, ?, ?, ?)", failed.table),
+		connection, queue, payload, exception, time.Now().UTC(),
+	)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// All 按失败时间倒序排列的所有记录，queue 不为空时只返回该队列的记录
+func (failed *FailedJobs) All(queue string) ([]FailedJob, error) {
+	var (
+		query = fmt.Sprintf("select id, connection, queue, payload, exception, failed_at from %s", failed.table)
+		args  = make([]any, 0)
+	)
+	if queue != "" {
+		query, args = query+" where queue = ?", append(args, queue)
+	}
+	return failed.query(query+" order by id desc", args...)
+}
+
+// Find 按 id 查找，不存在时返回 FailedJobNotFoundErr
+func (failed *FailedJobs) Find(ids ...int64) ([]FailedJob, error) {
+	if len(ids) == 0 {
+		return []FailedJob{}, nil
+	}
+	jobs, err := failed.query(
+		fmt.Sprintf("select id, connection, queue, payload, exception, failed_at from %s where id in (%s) order by id", failed.table, placeholders(len(ids))),
+		int64Args(ids)...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	var found = make(map[int64]bool)
+	for _, job := range jobs {
+		found[job.ID] = true
+	}
+	for _, id := range ids {
+		if !found[id] {
+			return nil, fmt.Errorf("%w：%d", FailedJobNotFoundErr, id)
+		}
+	}
+	return jobs, nil
+}
+
+// Lookup 按 ParseIDs 支持的格式查找，ids 为 all 时返回所有记录
+func (failed *FailedJobs) Lookup(ids string) ([]FailedJob, error) {
+	if ids == "all" {
+		return failed.All("")
+	}
+	parsed, err := ParseIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	return failed.Find(parsed...)
+}
+
+// Retry 把任务重置尝试次数后推回原来的连接和队列
+// 先删除记录再推送，推送失败时按原来的 id 恢复记录，同一条记录不会被重复推回队列
+// 单个任务失败不影响其他任务，返回成功的 id 以及每个失败任务的错误
+func (failed *FailedJobs) Retry(jobs ...FailedJob) ([]int64, error) {
+	var (
+		retried = make([]int64, 0, len(jobs))
+		errs    = make([]error, 0)
+	)
+	for _, item := range jobs {
+		if err := failed.retry(item); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		retried = append(retried, item.ID)
+	}
+	return retried, errors.Join(errs...)
+}
+
+func (failed *FailedJobs) retry(item FailedJob) error {
+	job, err := failed.serializer.Unserialize(item.Payload)
+	if err != nil {
+		return fmt.Errorf("%w：%d：%v", RetryFailedErr, item.ID, err)
+	}
+	resetAttempts(job)
+
+	// 删除成功才说明这条记录没有被其他进程重试
+	deleted, err := failed.Forget(item.ID)
+	if err != nil {
+		return fmt.Errorf("%w：%d：%v", RetryFailedErr, item.ID, err)
+	}
+	if deleted == 0 {
+		return fmt.Errorf("%w：%d", FailedJobNotFoundErr, item.ID)
+	}
+
+	var connection []string
+	if item.Connection != "" {
+		connection = []string{item.Connection}
+	}
+	if err = failed.queues.Connection(connection...).Push(job, item.Queue); err != nil {
+		if _, restoreErr := failed.db.Exec(
+			fmt.Sprintf("insert into %s (id, connection, queue, payload, exception, failed_at) values (?, ?, ?, ?, ?, ?)", failed.table),
+			item.ID, item.Connection, item.Queue, item.Payload, item.Exception, item.FailedAt.UTC(),
+		); restoreErr != nil {
+			return fmt.Errorf("%w：%d：%v，恢复记录失败：%v", RetryFailedErr, item.ID, err, restoreErr)
+		}
+		return fmt.Errorf("%w：%d：%v", RetryFailedErr, item.ID, err)
+	}
+	return nil
+}
+
+// Forget 删除给定的记录，返回删除的数量
+func (failed *FailedJobs) Forget(ids ...int64) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	return failed.exec(fmt.Sprintf("delete from %s where id in (%s)", failed.table, placeholders(len(ids))), int64Args(ids)...)
+}
+
+// Flush 删除失败时间早于 before 的记录，before 为零值时删除所有记录
+func (failed *FailedJobs) Flush(before time.Time) (int64, error) {
+	if before.IsZero() {
+		return failed.exec(fmt.Sprintf("delete from %s", failed.table))
+	}
+	return failed.exec(fmt.Sprintf("delete from %s where failed_at < ?", failed.table), before.UTC())
+}
+
+func (failed *FailedJobs) query(query string, args ...any) ([]FailedJob, error) {
+	var records = make([]failedRecord, 0)
+	if err := failed.db.Select(&records, query, args...); err != nil {
+		return nil, err
+	}
+	var jobs = make([]FailedJob, 0, len(records))
+	for _, record := range records {
+		jobs = append(jobs, FailedJob{
+			ID:         record.ID,
+			Connection: record.Connection,
+			Queue:      record.Queue,
+			Class:      className(record.Payload),
+			Payload:    record.Payload,
+			Exception:  record.Exception,
+			FailedAt:   migration.ParseTime(record.FailedAt),
+		})
+	}
+	return jobs, nil
+}
+
+func (failed *FailedJobs) exec(query string, args ...any) (int64, error) {
+	result, err := failed.db.Exec(query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// resetAttempts 重试前清空尝试次数和错误，否则会因为达到最大尝试次数直接失败
+func resetAttempts(job contracts.Job) {
+	if base := baseJob(job); base != nil {
+		base.Tries, base.Error, base.IsRelease = 0, nil, false
+	}
+}
+
+// baseJob 任务都嵌入了 *queue.Job，contracts.Job 没有提供修改尝试次数的方法，只能直接修改它
+func baseJob(job contracts.Job) *queue.Job {
+	value := reflect.ValueOf(job)
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+	if field := value.FieldByName("Job"); field.IsValid() && field.Type() == reflect.TypeOf(&queue.Job{}) && !field.IsNil() {
+		return field.Interface().(*queue.Job)
+	}
+	return nil
+}
+
+// MaxIDs ParseIDs 一次最多解析的 id 数量，范围按展开后的数量计算
+const MaxIDs = 1000
+
+// ParseIDs 解析命令行和接口中的 id 列表，例如 3、1,2,5 或者 10-20，展开后超过 MaxIDs 个时返回错误
+func ParseIDs(value string) ([]int64, error) {
+	var ids = make([]int64, 0)
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+		from, to, isRange := strings.Cut(part, "-")
+		start, err := strconv.ParseInt(strings.TrimSpace(from), 10, 64)
+		end := start
+		if err == nil && isRange {
+			end, err = strconv.ParseInt(strings.TrimSpace(to), 10, 64)
+		}
+		if err != nil || start <= 0 || end < start {
+			return nil, fmt.Errorf("%w：%s", InvalidIDsErr, part)
+		}
+		if end-start >= int64(MaxIDs-len(ids)) {
+			return nil, fmt.Errorf("%w：最多 %d 个：%s", InvalidIDsErr, MaxIDs, part)
+		}
+		for offset := int64(0); offset <= end-start; offset++ { // end 为最大的 int64 时 id++ 会溢出
+			ids = append(ids, start+offset)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("%w：%s", InvalidIDsErr, value)
+	}
+	return ids, nil
+}
+
+// className 任务的类名，只能从 json 序列化的任务中读取
+func className(payload string) string {
+	var class serialization.Class
+	if err := json.Unmarshal([]byte(payload), &class); err != nil {
+		return ""
+	}
+	return class.Class
+}
+
+func placeholders(count int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", count), ", ")
+}
+
+func int64Args(values []int64) []any {
+	var args = make([]any, 0, len(values))
+	for _, value := range values {
+		args = append(args, value)
+	}
+	return args
+}
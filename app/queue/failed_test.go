@@ -0,0 +1,84 @@
+package queue
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func sequence(start, end int64) []int64 {
+	var ids = make([]int64, 0, end-start+1)
+	for id := start; id <= end; id++ {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func TestParseIDs(t *testing.T) {
+	cases := []struct {
+		value    string
+		expected []int64
+	}{
+		{"3", []int64{3}},
+		{" 3 ", []int64{3}},
+		{"1,2,5", []int64{1, 2, 5}},
+		{"1, 2,,5,", []int64{1, 2, 5}},
+		{"10-12", []int64{10, 11, 12}},
+		{"7-7", []int64{7}},
+		{"1,5 - 6,9", []int64{1, 5, 6, 9}},
+		{"1-1000", sequence(1, 1000)},
+		{"1-999,2000", append(sequence(1, 999), 2000)},
+		{"9223372036854775806-9223372036854775807", []int64{9223372036854775806, 9223372036854775807}},
+	}
+	for _, item := range cases {
+		t.Run(item.value, func(t *testing.T) {
+			ids, err := ParseIDs(item.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(ids, item.expected) {
+				t.Errorf("ids = %v, want %v", ids, item.expected)
+			}
+		})
+	}
+}
+
+func TestParseIDsInvalid(t *testing.T) {
+	cases := []struct {
+		value string
+		limit bool // 超过 MaxIDs
+	}{
+		{value: ""},
+		{value: " , ,"},
+		{value: "all"},
+		{value: "0"},
+		{value: "-3"},
+		{value: "3-"},
+		{value: "5-3"},
+		{value: "1-2-3"},
+		{value: "1.5"},
+		{value: "1,a"},
+		{value: "99999999999999999999"},
+		{value: "1-1001", limit: true},
+		{value: "1-9999999999", limit: true},
+		{value: "1-999,2000,2001", limit: true},
+		{value: "1-9223372036854775807", limit: true},
+		{value: strings.TrimSuffix(strings.Repeat("1,", MaxIDs+1), ","), limit: true},
+	}
+	for _, item := range cases {
+		t.Run(fmt.Sprintf("%.40s", item.value), func(t *testing.T) {
+			ids, err := ParseIDs(item.value)
+			if !errors.Is(err, InvalidIDsErr) {
+				t.Fatalf("err = %v, want %v", err, InvalidIDsErr)
+			}
+			if ids != nil {
+				t.Errorf("ids = %v, want nil", ids)
+			}
+			if limit := strings.Contains(err.Error(), fmt.Sprintf("最多 %d 个", MaxIDs)); limit != item.limit {
+				t.Errorf("err = %v, limit = %v, want %v", err, limit, item.limit)
+			}
+		})
+	}
+}
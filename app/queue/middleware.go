@@ -0,0 +1,160 @@
+package queue
+
+import (
+	"errors"
+	"fmt"
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/goal/app/locks"
+	"github.com/goal-web/supports/logs"
+	"go.uber.org/ratelimit"
+	"math"
+	"time"
+)
+
+var JobReleasedErr = errors.New("任务被放回队列")
+
+// JobMiddleware 包裹任务的执行，next 执行后面的中间件和任务本身
+// 返回 Release 的结果时任务放回队列，不计入尝试次数；返回其他错误时按失败处理
+type JobMiddleware interface {
+	Handle(app contracts.Application, job contracts.Job, next func() error) error
+}
+
+// HasMiddleware 声明中间件的任务，按顺序执行，只有 worker 消费的任务会经过中间件
+type HasMiddleware interface {
+	Middleware() []JobMiddleware
+}
+
+// Backoff 第 attempts 次尝试失败后到下一次尝试的等待时间
+type Backoff func(attempts int) time.Duration
+
+// HasBackoff 声明重试间隔的任务，没有实现时使用任务的 RetryInterval
+type HasBackoff interface {
+	Backoff() Backoff
+}
+
+// HasRetryUntil 声明重试截止时间的任务，返回零值时不限制
+// 截止时间不为零时不再限制尝试次数，任务在截止时间之后不会再被重试
+// 任务每次都会被反序列化，截止时间应该根据 CreatedAt 等保存在任务中的字段计算
+type HasRetryUntil interface {
+	RetryUntil() time.Time
+}
+
+// Delays 按顺序使用给定的间隔，尝试次数超过给定的数量后一直使用最后一个
+func Delays(delays ...time.Duration) Backoff {
+	return func(attempts int) time.Duration {
+		if len(delays) == 0 {
+			return 0
+		}
+		if attempts < 1 {
+			attempts = 1
+		}
+		if attempts > len(delays) {
+			attempts = len(delays)
+		}
+		return delays[attempts-1]
+	}
+}
+
+// Exponential 第 n 次失败后等待 base * 2^(n-1)，不超过 max，max 为 0 时不限制
+func Exponential(base, max time.Duration) Backoff {
+	return func(attempts int) time.Duration {
+		if attempts < 1 {
+			attempts = 1
+		}
+		delay := float64(base) * math.Pow(2, float64(attempts-1))
+		if delay > math.MaxInt64 || (max > 0 && delay > float64(max)) {
+			if max > 0 {
+				return max
+			}
+			return math.MaxInt64
+		}
+		return time.Duration(delay)
+	}
+}
+
+type released struct {
+	delay time.Duration
+}
+
+func (err *released) Error() string {
+	return fmt.Sprintf("%s：%s", JobReleasedErr.Error(), err.delay)
+}
+
+func (err *released) Unwrap() error {
+	return JobReleasedErr
+}
+
+// Release 中间件返回该错误时任务在 delay 之后重新执行
+func Release(delay time.Duration) error {
+	return &released{delay: delay}
+}
+
+type rateLimited struct {
+	name string
+	rate int
+	per  time.Duration
+}
+
+// RateLimited 同名的任务在当前进程中每 per 时间最多执行 rate 次，超过时等待，per 为 0 时为一秒
+// 限流器由 ratelimiter 服务按名称保存，同名的限流器以第一次创建时的参数为准
+func RateLimited(name string, rate int, per time.Duration) JobMiddleware {
+	if per <= 0 {
+		per = time.Second
+	}
+	return &rateLimited{name: name, rate: rate, per: per}
+}
+
+func (limited *rateLimited) Handle(app contracts.Application, _ contracts.Job, next func() error) error {
+	app.Get("ratelimiter").(contracts.RateLimiter).Limiter("queue:"+limited.name, func() contracts.Limiter {
+		return ratelimit.New(limited.rate, ratelimit.Per(limited.per))
+	}).Take()
+	return next()
+}
+
+// DefaultReleaseAfter WithoutOverlapping 的锁被占用时默认的重试间隔
+const DefaultReleaseAfter = 5 * time.Second
+
+// Overlapping 同一个 key 的任务同时只执行一个，锁被占用时把任务放回队列
+type Overlapping struct {
+	key          string
+	releaseAfter time.Duration
+	expiresAfter time.Duration
+}
+
+// WithoutOverlapping 锁使用 queue.locks 绑定的存储，即 config/queue.go 中 queue_locks 配置的存储，多个 worker 进程需要使用同一个 redis
+func WithoutOverlapping(key string) *Overlapping {
+	return &Overlapping{key: key, releaseAfter: DefaultReleaseAfter}
+}
+
+// ReleaseAfter 锁被占用时任务重新执行的间隔
+func (overlapping *Overlapping) ReleaseAfter(delay time.Duration) *Overlapping {
+	overlapping.releaseAfter = delay
+	return overlapping
+}
+
+// ExpireAfter 锁的过期时间，防止 worker 中途退出后锁不被释放，默认为任务的 Timeout，没有设置时使用 locks.DefaultTTL
+func (overlapping *Overlapping) ExpireAfter(ttl time.Duration) *Overlapping {
+	overlapping.expiresAfter = ttl
+	return overlapping
+}
+
+func (overlapping *Overlapping) Handle(app contracts.Application, job contracts.Job, next func() error) error {
+	var ttl = overlapping.expiresAfter
+	if ttl <= 0 && job.GetTimeout() > 0 {
+		ttl = time.Duration(job.GetTimeout()) * time.Second
+	}
+	lock := locks.New(app.Get("queue.locks").(locks.Store), "queue:overlapping:"+overlapping.key, ttl)
+	acquired, err := lock.Acquire()
+	if err != nil {
+		return err
+	}
+	if !acquired {
+		return Release(overlapping.releaseAfter)
+	}
+	defer func() {
+		if err := lock.Release(); err != nil {
+			logs.WithError(err).WithField("job", job.Uuid()).Warn("queue.Overlapping: release lock failed")
+		}
+	}()
+	return next()
+}
@@ -0,0 +1,65 @@
+package queue
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	cases := []struct {
+		name     string
+		backoff  Backoff
+		attempts []int
+		expected []time.Duration
+	}{
+		{
+			name:     "delays in order, then the last one",
+			backoff:  Delays(time.Second, 10*time.Second, time.Minute),
+			attempts: []int{1, 2, 3, 4, 10},
+			expected: []time.Duration{time.Second, 10 * time.Second, time.Minute, time.Minute, time.Minute},
+		},
+		{
+			name:     "delays before the first attempt",
+			backoff:  Delays(time.Second, time.Minute),
+			attempts: []int{0, -1},
+			expected: []time.Duration{time.Second, time.Second},
+		},
+		{
+			name:     "no delays",
+			backoff:  Delays(),
+			attempts: []int{0, 1, 5},
+			expected: []time.Duration{0, 0, 0},
+		},
+		{
+			name:     "exponential doubles up to max",
+			backoff:  Exponential(5*time.Second, time.Minute),
+			attempts: []int{0, 1, 2, 3, 4, 5, 6, 100},
+			expected: []time.Duration{5 * time.Second, 5 * time.Second, 10 * time.Second, 20 * time.Second, 40 * time.Second, time.Minute, time.Minute, time.Minute},
+		},
+		{
+			name:     "exponential without max",
+			backoff:  Exponential(time.Second, 0),
+			attempts: []int{1, 2, 11, 64, 10000},
+			expected: []time.Duration{time.Second, 2 * time.Second, 1024 * time.Second, math.MaxInt64, math.MaxInt64},
+		},
+		{
+			name:     "exponential with zero base",
+			backoff:  Exponential(0, time.Minute),
+			attempts: []int{1, 10},
+			expected: []time.Duration{0, 0},
+		},
+	}
+	for _, item := range cases {
+		t.Run(item.name, func(t *testing.T) {
+			var delays = make([]time.Duration, 0, len(item.attempts))
+			for _, attempts := range item.attempts {
+				delays = append(delays, item.backoff(attempts))
+			}
+			if !reflect.DeepEqual(delays, item.expected) {
+				t.Errorf("delays = %v, want %v", delays, item.expected)
+			}
+		})
+	}
+}
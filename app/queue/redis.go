@@ -0,0 +1,184 @@
+package queue
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/supports/logs"
+	"github.com/goal-web/supports/utils"
+	"sync"
+	"time"
+)
+
+// Redis 每个队列使用三个 key：待执行的任务列表 <prefix><queue>，延迟任务的有序集合 <prefix><queue>:delayed，
+// 已取出未确认任务的有序集合 <prefix><queue>:reserved，分数为可执行的时间和确认的截止时间
+// 取出时先把到期的延迟任务和超过 retry_after 秒未确认的任务（例如 worker 中途退出）移回列表，这些操作都在 lua 脚本中原子执行
+type Redis struct {
+	name         string
+	redis        contracts.RedisConnection
+	prefix       string
+	defaultQueue string
+	retryAfter   time.Duration
+	serializer   contracts.JobSerializer
+
+	stop chan struct{}
+	once sync.Once
+}
+
+// RedisDriver 配置中的 connection 为 config/redis.go 中的连接，prefix 默认为 queues:，retry_after 默认为 90 秒
+func RedisDriver(app contracts.Application) contracts.QueueDriver {
+	return func(name string, config contracts.Fields, serializer contracts.JobSerializer) contracts.Queue {
+		// redis 的连接名为空时不会使用默认连接
+		var connection []string
+		if store := utils.GetStringField(config, "connection"); store != "" {
+			connection = []string{store}
+		}
+		return &Redis{
+			name:         name,
+			redis:        app.Get("redis.factory").(contracts.RedisFactory).Connection(connection...),
+			prefix:       utils.GetStringField(config, "prefix", "queues:"),
+			defaultQueue: utils.GetStringField(config, "default", "default"),
+			retryAfter:   time.Duration(utils.GetInt64Field(config, "retry_after", 90)) * time.Second,
+			serializer:   serializer,
+			stop:         make(chan struct{}),
+		}
+	}
+}
+
+// envelope 保存在 redis 中的任务，id 保证相同的任务在有序集合中也是不同的成员
+type envelope struct {
+	ID       string `json:"id"`
+	Attempts int    `json:"attempts"`
+	Job      string `json:"job"`
+}
+
+func (queue *Redis) Push(job contracts.Job, queues ...string) error {
+	return queue.Later(time.Now(), job, queues...)
+}
+
+func (queue *Redis) PushOn(name string, job contracts.Job) error {
+	return queue.LaterOn(name, time.Now(), job)
+}
+
+func (queue *Redis) PushRaw(payload, name string, _ ...contracts.Fields) error {
+	return queue.add(name, payload, 0, time.Now())
+}
+
+func (queue *Redis) Later(delay time.Time, job contracts.Job, queues ...string) error {
+	if len(queues) > 0 && queues[0] != "" {
+		return queue.LaterOn(queues[0], delay, job)
+	}
+	if job.GetQueue() != "" {
+		return queue.LaterOn(job.GetQueue(), delay, job)
+	}
+	return queue.LaterOn(queue.defaultQueue, delay, job)
+}
+
+func (queue *Redis) LaterOn(name string, delay time.Time, job contracts.Job) error {
+	job.SetQueue(name)
+	return queue.add(name, queue.serializer.Serializer(job), job.GetAttemptsNum(), delay)
+}
+
+func (queue *Redis) GetConnectionName() string {
+	return queue.name
+}
+
+func (queue *Redis) Release(job contracts.Job, delay ...int) error {
+	var at = time.Now()
+	if len(delay) > 0 {
+		at = at.Add(time.Duration(delay[0]) * time.Second)
+	}
+	return queue.Later(at, job)
+}
+
+// popScript KEYS 为列表、延迟集合、未确认集合，ARGV 为当前时间和确认的截止时间
+// 返回原始的任务和放入未确认集合中的成员，队列为空时返回空数组
+const popScript = `
+for _, key in ipairs({KEYS[2], KEYS[3]}) do
+	local due = redis.call('zrangebyscore', key, '-inf', ARGV[1])
+	if #due > 0 then
+		redis.call('zremrangebyscore', key, '-inf', ARGV[1])
+		for i = 1, #due, 100 do
+			redis.call('rpush', KEYS[1], unpack(due, i, math.min(i + 99, #due)))
+		end
+	end
+end
+local job = redis.call('lpop', KEYS[1])
+if not job then
+	return {}
+end
+local reserved = cjson.decode(job)
+reserved['attempts'] = reserved['attempts'] + 1
+reserved = cjson.encode(reserved)
+redis.call('zadd', KEYS[3], ARGV[2], reserved)
+return {job, reserved}
+`
+
+// Pop 取出给定队列中的下一个任务，确认时从未确认集合中删除
+func (queue *Redis) Pop(name string) (*contracts.Msg, error) {
+	var now = time.Now()
+	result, err := queue.redis.Eval(popScript, queue.keys(name), score(now), score(now.Add(queue.retryAfter)))
+	if err != nil {
+		return nil, err
+	}
+	values, _ := result.([]any)
+	if len(values) != 2 {
+		return nil, nil
+	}
+
+	var (
+		reserved = utils.ToString(values[1], "")
+		item     envelope
+	)
+	if err = json.Unmarshal([]byte(utils.ToString(values[0], "")), &item); err != nil {
+		return nil, fmt.Errorf("%s：%w", name, err)
+	}
+	job, err := queue.serializer.Unserialize(item.Job)
+	if err != nil {
+		return nil, fmt.Errorf("%s：%s：%w", name, item.ID, err)
+	}
+	if base := baseJob(job); base != nil {
+		base.Tries = item.Attempts
+	}
+	return &contracts.Msg{
+		Job: job,
+		Ack: func() {
+			if _, err := queue.redis.ZRem(queue.prefix+name+":reserved", reserved); err != nil {
+				logs.WithError(err).WithField("id", item.ID).Error("queue.Redis: ack failed")
+			}
+		},
+	}, nil
+}
+
+// Listen 按给定顺序轮询队列，queue:work 直接使用 Pop
+func (queue *Redis) Listen(queues ...string) chan contracts.Msg {
+	return listen(queue, queues, queue.stop)
+}
+
+func (queue *Redis) Stop() {
+	queue.once.Do(func() {
+		close(queue.stop)
+	})
+}
+
+func (queue *Redis) add(name, payload string, attempts int, available time.Time) error {
+	member, err := json.Marshal(envelope{ID: utils.RandStr(32), Attempts: attempts, Job: payload})
+	if err != nil {
+		return err
+	}
+	if !available.After(time.Now()) {
+		_, err = queue.redis.RPush(queue.prefix+name, string(member))
+		return err
+	}
+	_, err = queue.redis.ZAdd(queue.prefix+name+":delayed", &contracts.Z{Score: float64(available.UnixMilli()) / 1000, Member: string(member)})
+	return err
+}
+
+func (queue *Redis) keys(name string) []string {
+	return []string{queue.prefix + name, queue.prefix + name + ":delayed", queue.prefix + name + ":reserved"}
+}
+
+// score 有序集合的分数，精确到毫秒的 unix 时间
+func score(at time.Time) string {
+	return fmt.Sprintf("%.3f", float64(at.UnixMilli())/1000)
+}
@@ -0,0 +1,186 @@
+package queue
+
+import (
+	"github.com/alicebob/miniredis/v2"
+	"github.com/goal-web/application"
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/redis"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// redisConfig 只提供 redis 配置
+type redisConfig struct {
+	contracts.Config
+	redis redis.Config
+}
+
+func (config redisConfig) Get(string) any {
+	return config.redis
+}
+
+// ignoreExceptions redis 服务需要注入异常处理器
+type ignoreExceptions struct {
+	contracts.ExceptionHandler
+}
+
+// newRedis 通过 RedisDriver 创建连接到内存 redis 的队列
+func newRedis(t *testing.T, retryAfter int) (*Redis, *miniredis.Miniredis) {
+	t.Helper()
+	var (
+		server = miniredis.RunT(t)
+		app    = application.New()
+	)
+	app.Singleton("config", func() contracts.Config {
+		return redisConfig{redis: redis.Config{
+			Default: "default",
+			Stores:  map[string]contracts.Fields{"default": {"host": server.Host(), "port": server.Port()}},
+		}}
+	})
+	app.Singleton("exceptions.handler", func() contracts.ExceptionHandler { return ignoreExceptions{} })
+	redis.NewService().Register(app)
+
+	queue := RedisDriver(app)("redis", contracts.Fields{"retry_after": retryAfter}, newSerializer())
+	return queue.(*Redis), server
+}
+
+func TestRedisPop(t *testing.T) {
+	cases := []struct {
+		name     string
+		push     func(queue *Redis)
+		queues   []string
+		expected []string
+	}{
+		{
+			name: "jobs are popped in order",
+			push: func(queue *Redis) {
+				_ = queue.Push(newSampleJob("a"))
+				_ = queue.Push(newSampleJob("b"))
+			},
+			queues:   []string{"default"},
+			expected: []string{"a", "b"},
+		},
+		{
+			name: "queues are popped by priority",
+			push: func(queue *Redis) {
+				_ = queue.Push(newSampleJob("low"))
+				_ = queue.PushOn("high", newSampleJob("high"))
+				_ = queue.PushOn("slow", newSampleJob("slow"))
+			},
+			queues:   []string{"high", "default", "slow"},
+			expected: []string{"high", "low", "slow"},
+		},
+		{
+			name: "other queues are ignored",
+			push: func(queue *Redis) {
+				_ = queue.Push(newSampleJob("a"), "slow")
+				_ = queue.Push(newSampleJob("b"))
+			},
+			queues:   []string{"default"},
+			expected: []string{"b"},
+		},
+		{
+			name: "delayed jobs are not available yet",
+			push: func(queue *Redis) {
+				_ = queue.Later(time.Now().Add(time.Hour), newSampleJob("later"))
+				_ = queue.Release(newSampleJob("released"), 60)
+				_ = queue.Later(time.Now().Add(-time.Second), newSampleJob("due"))
+			},
+			queues:   []string{"default"},
+			expected: []string{"due"},
+		},
+		{
+			name: "identical jobs are kept apart",
+			push: func(queue *Redis) {
+				_ = queue.Later(time.Now().Add(100*time.Millisecond), newSampleJob("a"))
+				_ = queue.Later(time.Now().Add(100*time.Millisecond), newSampleJob("a"))
+				time.Sleep(150 * time.Millisecond)
+			},
+			queues:   []string{"default"},
+			expected: []string{"a", "a"},
+		},
+	}
+	for _, item := range cases {
+		t.Run(item.name, func(t *testing.T) {
+			queue, _ := newRedis(t, 90)
+			item.push(queue)
+			if names, _ := popNames(t, queue, item.queues...); !reflect.DeepEqual(names, item.expected) {
+				t.Errorf("popped %v, want %v", names, item.expected)
+			}
+		})
+	}
+}
+
+func TestRedisDelayed(t *testing.T) {
+	queue, server := newRedis(t, 90)
+	_ = queue.Later(time.Now().Add(200*time.Millisecond), newSampleJob("a"))
+
+	if members, _ := server.ZMembers("queues:default:delayed"); len(members) != 1 {
+		t.Fatalf("delayed = %v, want one job", members)
+	}
+	if names, _ := popNames(t, queue, "default"); len(names) > 0 {
+		t.Fatalf("popped %v before the delay", names)
+	}
+	time.Sleep(250 * time.Millisecond)
+	if names, _ := popNames(t, queue, "default"); !reflect.DeepEqual(names, []string{"a"}) {
+		t.Errorf("popped %v after the delay, want [a]", names)
+	}
+}
+
+func TestRedisReservation(t *testing.T) {
+	t.Run("reserved jobs are not popped again and ack removes them", func(t *testing.T) {
+		queue, server := newRedis(t, 90)
+		_ = queue.Push(newSampleJob("a"))
+
+		msg, err := queue.Pop("default")
+		if err != nil || msg == nil {
+			t.Fatalf("Pop = %v, %v", msg, err)
+		}
+		if attempts := msg.Job.GetAttemptsNum(); attempts != 0 {
+			t.Errorf("attempts = %d, want 0", attempts)
+		}
+		if names, _ := popNames(t, queue, "default"); len(names) > 0 {
+			t.Errorf("reserved job popped again: %v", names)
+		}
+		if members, _ := server.ZMembers("queues:default:reserved"); len(members) != 1 {
+			t.Errorf("reserved = %v, want one job", members)
+		}
+		msg.Ack()
+		if server.Exists("queues:default:reserved") {
+			t.Error("reserved job left after ack")
+		}
+	})
+
+	// worker 中途退出的那次也计入尝试次数
+	t.Run("jobs not acked within retry_after are popped again", func(t *testing.T) {
+		queue, _ := newRedis(t, 0)
+		_ = queue.Push(newSampleJob("a"))
+
+		if msg, err := queue.Pop("default"); err != nil || msg == nil {
+			t.Fatalf("Pop = %v, %v", msg, err)
+		}
+		time.Sleep(10 * time.Millisecond)
+		msg, err := queue.Pop("default")
+		if err != nil || msg == nil {
+			t.Fatalf("expired reservation not popped again: %v, %v", msg, err)
+		}
+		if attempts := msg.Job.GetAttemptsNum(); attempts != 1 {
+			t.Errorf("attempts = %d, want 1", attempts)
+		}
+	})
+
+	t.Run("released jobs keep their attempts", func(t *testing.T) {
+		queue, _ := newRedis(t, 90)
+		_ = queue.Push(newSampleJob("a"))
+		msg, _ := queue.Pop("default")
+		msg.Job.IncrementAttemptsNum() // 与 worker 执行任务前一样
+		msg.Ack()
+		if err := queue.Release(msg.Job); err != nil {
+			t.Fatal(err)
+		}
+		if _, attempts := popNames(t, queue, "default"); !reflect.DeepEqual(attempts, []int{1}) {
+			t.Errorf("attempts = %v, want [1]", attempts)
+		}
+	})
+}
@@ -0,0 +1,82 @@
+package queue
+
+import (
+	"errors"
+	"fmt"
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/supports/utils"
+	"time"
+)
+
+var SyncJobFailedErr = errors.New("同步任务执行失败")
+
+// Sync 在 Push 时直接执行任务，用于本地开发和测试
+// 任务会先序列化再反序列化，与真正入队时的行为一致；Later、Release 的延迟被忽略，立即执行
+type Sync struct {
+	name         string
+	defaultQueue string
+	serializer   contracts.JobSerializer
+}
+
+func SyncDriver(name string, config contracts.Fields, serializer contracts.JobSerializer) contracts.Queue {
+	return &Sync{
+		name:         name,
+		defaultQueue: utils.GetStringField(config, "default", "default"),
+		serializer:   serializer,
+	}
+}
+
+func (sync *Sync) Push(job contracts.Job, queue ...string) error {
+	if len(queue) > 0 && queue[0] != "" {
+		return sync.PushOn(queue[0], job)
+	}
+	if job.GetQueue() != "" {
+		return sync.PushOn(job.GetQueue(), job)
+	}
+	return sync.PushOn(sync.defaultQueue, job)
+}
+
+func (sync *Sync) PushOn(queue string, job contracts.Job) error {
+	job.SetQueue(queue)
+	return sync.PushRaw(sync.serializer.Serializer(job), queue)
+}
+
+// PushRaw 反序列化后执行，任务 panic 时返回 SyncJobFailedErr
+func (sync *Sync) PushRaw(payload, queue string, _ ...contracts.Fields) (err error) {
+	job, err := sync.serializer.Unserialize(payload)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("%w：%s：%v", SyncJobFailedErr, queue, recovered)
+		}
+	}()
+	job.IncrementAttemptsNum()
+	job.Handle()
+	return nil
+}
+
+func (sync *Sync) Later(_ time.Time, job contracts.Job, queue ...string) error {
+	return sync.Push(job, queue...)
+}
+
+func (sync *Sync) LaterOn(queue string, _ time.Time, job contracts.Job) error {
+	return sync.PushOn(queue, job)
+}
+
+func (sync *Sync) GetConnectionName() string {
+	return sync.name
+}
+
+func (sync *Sync) Release(job contracts.Job, _ ...int) error {
+	return sync.Push(job)
+}
+
+// Listen 任务在 Push 时已经执行，没有需要消费的任务
+func (sync *Sync) Listen(...string) chan contracts.Msg {
+	return make(chan contracts.Msg)
+}
+
+func (sync *Sync) Stop() {
+}
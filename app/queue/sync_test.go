@@ -0,0 +1,91 @@
+package queue
+
+import (
+	"errors"
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/queue"
+	"github.com/goal-web/serialization"
+	"github.com/goal-web/supports/class"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+var sampleJobClass = class.Any(sampleJob{})
+
+// sampleJob 测试用的任务，执行时记录名称，Panic 为 true 时执行失败
+type sampleJob struct {
+	*queue.Job
+	Name  string `json:"name"`
+	Panic bool   `json:"panic"`
+}
+
+func (job *sampleJob) Handle() {
+	if job.Panic {
+		panic(job.Name)
+	}
+	handled.Lock()
+	defer handled.Unlock()
+	handled.names = append(handled.names, job.Name)
+}
+
+var handled struct {
+	sync.Mutex
+	names []string
+}
+
+// handledJobs 返回并清空已经执行的任务
+func handledJobs() []string {
+	handled.Lock()
+	defer handled.Unlock()
+	names := handled.names
+	handled.names = nil
+	return names
+}
+
+func newSampleJob(name string) *sampleJob {
+	return &sampleJob{Job: &queue.Job{UUID: name, CreatedAt: time.Now().Unix(), MaxTries: 3}, Name: name}
+}
+
+func newSerializer() contracts.JobSerializer {
+	classes := serialization.NewClassSerializer(nil)
+	classes.Register(sampleJobClass)
+	return queue.NewJobSerializer(classes)
+}
+
+func TestSync(t *testing.T) {
+	var (
+		failed = newSampleJob("failed")
+		driver = SyncDriver("sync", contracts.Fields{"default": "low"}, newSerializer())
+	)
+	failed.Panic = true
+
+	cases := []struct {
+		name    string
+		push    func(job contracts.Job) error
+		job     *sampleJob
+		queue   string
+		handled []string
+		err     error
+	}{
+		{"push on the default queue", func(job contracts.Job) error { return driver.Push(job) }, newSampleJob("a"), "low", []string{"a"}, nil},
+		{"push on a given queue", func(job contracts.Job) error { return driver.Push(job, "high") }, newSampleJob("b"), "high", []string{"b"}, nil},
+		{"later runs immediately", func(job contracts.Job) error { return driver.Later(time.Now().Add(time.Hour), job) }, newSampleJob("c"), "low", []string{"c"}, nil},
+		{"release runs immediately", func(job contracts.Job) error { return driver.Release(job, 60) }, newSampleJob("d"), "low", []string{"d"}, nil},
+		{"panics are returned", func(job contracts.Job) error { return driver.PushOn("high", job) }, failed, "high", nil, SyncJobFailedErr},
+	}
+	for _, item := range cases {
+		t.Run(item.name, func(t *testing.T) {
+			if err := item.push(item.job); !errors.Is(err, item.err) {
+				t.Fatalf("err = %v, want %v", err, item.err)
+			}
+			if item.job.GetQueue() != item.queue {
+				t.Errorf("queue = %q, want %q", item.job.GetQueue(), item.queue)
+			}
+			if names := handledJobs(); !reflect.DeepEqual(names, item.handled) {
+				t.Errorf("handled = %v, want %v", names, item.handled)
+			}
+		})
+	}
+}
@@ -0,0 +1,437 @@
+package queue
+
+import (
+	"errors"
+	"fmt"
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/queue"
+	"github.com/goal-web/supports/exceptions"
+	"github.com/goal-web/supports/logs"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+var (
+	JobTimeoutErr          = errors.New("任务执行超时")
+	WorkerFailedErr        = errors.New("worker 异常退出")
+	MaxAttemptsExceededErr = errors.New("任务超过最大尝试次数")
+	RetryUntilExpiredErr   = errors.New("任务超过重试截止时间")
+	NoWorkersErr           = errors.New("没有可以启动的 worker")
+)
+
+// DefaultSleep 队列为空时默认的等待时间
+const DefaultSleep = 3 * time.Second
+
+// MinSleep 队列为空时最短的等待时间，避免 --sleep=0 时空闲的 worker 不停地轮询数据库或者 redis
+const MinSleep = 100 * time.Millisecond
+
+// WorkerOptions 一个 worker 的参数，对应 queue:work 的选项
+type WorkerOptions struct {
+	Name       string
+	Connection string        // 为空时使用默认连接
+	Queues     []string      // 按优先级排列，只有支持 Poller 的驱动按顺序取任务
+	Processes  int           // 同时执行的任务数
+	Tries      int           // 任务没有设置 MaxTries 时的最大尝试次数
+	Timeout    time.Duration // 任务没有设置 Timeout 时的超时时间，为 0 时不限制
+	Sleep      time.Duration // 队列为空时的等待时间，小于 MinSleep 时按 MinSleep 处理
+	MaxJobs    int           // 处理完给定数量的任务后退出，为 0 时不限制
+	MaxTime    time.Duration // 运行给定时间后退出，为 0 时不限制
+	Once       bool          // 只处理下一个任务，队列为空时直接退出
+}
+
+// Poller 可以从指定队列取出一个任务的驱动，队列为空时返回 nil
+// worker 按 WorkerOptions.Queues 的顺序轮询，其余驱动通过 Listen 同时消费所有队列
+type Poller interface {
+	Pop(queue string) (*contracts.Msg, error)
+}
+
+// listen 为 Poller 实现 Listen，按给定顺序轮询队列，都为空时等待 DefaultSleep
+func listen(poller Poller, queues []string, stop <-chan struct{}) chan contracts.Msg {
+	var ch = make(chan contracts.Msg)
+	go func() {
+		for {
+			msg := pop(poller, queues)
+			if msg == nil {
+				select {
+				case <-time.After(DefaultSleep):
+					continue
+				case <-stop:
+					return
+				}
+			}
+			select {
+			case ch <- *msg:
+			case <-stop:
+				return
+			}
+		}
+	}()
+	return ch
+}
+
+// pop 按顺序从第一个不为空的队列中取出任务，出错的队列跳过
+func pop(poller Poller, queues []string) *contracts.Msg {
+	for _, name := range queues {
+		msg, err := poller.Pop(name)
+		if err != nil {
+			logs.WithError(err).WithField("queue", name).Warn("queue.Worker: pop failed")
+			continue
+		}
+		if msg != nil {
+			return msg
+		}
+	}
+	return nil
+}
+
+// Worker 消费一个队列连接，失败的任务按尝试次数重新入队或者记录到 FailedJobs
+// 超时的任务会被当作失败处理，但是已经启动的 Handle 无法被中断，要等它真正返回后才会释放中间件的锁并重新入队，
+// 避免超时的任务和它的重试同时执行，等待期间继续占用一个并发数
+// 任务实现 HasMiddleware、HasBackoff、HasRetryUntil 时按声明的中间件执行，并按声明的间隔和截止时间重试
+type Worker struct {
+	app        contracts.Application
+	options    WorkerOptions
+	queue      contracts.Queue
+	failed     *FailedJobs
+	serializer contracts.JobSerializer
+	handler    contracts.ExceptionHandler
+
+	stop    chan struct{}
+	done    chan struct{}
+	once    sync.Once
+	running sync.WaitGroup
+}
+
+func NewWorker(app contracts.Application, queue contracts.Queue, failed *FailedJobs, serializer contracts.JobSerializer, handler contracts.ExceptionHandler, options WorkerOptions) *Worker {
+	if options.Processes <= 0 {
+		options.Processes = 1
+	}
+	if options.Once {
+		options.MaxJobs = 1
+	}
+	if options.Sleep < MinSleep {
+		options.Sleep = MinSleep
+	}
+	return &Worker{
+		app:        app,
+		options:    options,
+		queue:      queue,
+		failed:     failed,
+		serializer: serializer,
+		handler:    handler,
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+}
+
+// Work 开始消费，直到调用 Stop 或者达到 MaxJobs、MaxTime 的限制，返回前会等待执行中的任务完成
+// 驱动连接失败等异常导致无法继续消费时返回错误
+func (worker *Worker) Work() (err error) {
+	defer close(worker.done)
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("%w：%s：%v", WorkerFailedErr, worker.options.Name, recovered)
+		}
+	}()
+
+	var (
+		slots     = make(chan struct{}, worker.options.Processes)
+		processed = 0
+		deadline  <-chan time.Time
+		next      = worker.next()
+	)
+	if worker.options.MaxTime > 0 {
+		timer := time.NewTimer(worker.options.MaxTime)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+	logs.Default().Info(fmt.Sprintf("queue.Worker: %s is working on %s %v", worker.options.Name, worker.queue.GetConnectionName(), worker.options.Queues))
+	defer func() {
+		worker.running.Wait()
+		if _, isPoller := worker.queue.(Poller); !isPoller {
+			worker.queue.Stop()
+		}
+		logs.Default().Info(fmt.Sprintf("queue.Worker: %s stopped after %d jobs", worker.options.Name, processed))
+	}()
+
+	for worker.options.MaxJobs <= 0 || processed < worker.options.MaxJobs {
+		select {
+		case slots <- struct{}{}:
+		case <-worker.stop:
+			return nil
+		}
+		msg, ok := next(deadline)
+		if !ok {
+			<-slots
+			return nil
+		}
+		processed++
+		worker.running.Add(1)
+		go func() {
+			defer func() {
+				<-slots
+				worker.running.Done()
+			}()
+			worker.process(msg)
+		}()
+	}
+	return nil
+}
+
+// Stop 停止取新的任务，执行中的任务由 Work 等待完成
+func (worker *Worker) Stop() {
+	worker.once.Do(func() {
+		close(worker.stop)
+	})
+}
+
+// Wait 等待 Work 返回
+func (worker *Worker) Wait() {
+	<-worker.done
+}
+
+// next 取下一个任务的函数，停止、超过 MaxTime 或者 Once 时队列为空返回 false
+func (worker *Worker) next() func(deadline <-chan time.Time) (contracts.Msg, bool) {
+	if poller, isPoller := worker.queue.(Poller); isPoller {
+		return func(deadline <-chan time.Time) (contracts.Msg, bool) {
+			for {
+				if msg := pop(poller, worker.options.Queues); msg != nil {
+					return *msg, true
+				}
+				if worker.options.Once {
+					return contracts.Msg{}, false
+				}
+				select {
+				case <-time.After(worker.options.Sleep):
+				case <-worker.stop:
+					return contracts.Msg{}, false
+				case <-deadline:
+					return contracts.Msg{}, false
+				}
+			}
+		}
+	}
+
+	var messages = worker.queue.Listen(worker.options.Queues...)
+	return func(deadline <-chan time.Time) (contracts.Msg, bool) {
+		select {
+		case msg := <-messages:
+			return msg, true
+		case <-worker.stop:
+			return contracts.Msg{}, false
+		case <-deadline:
+			return contracts.Msg{}, false
+		}
+	}
+}
+
+// process 执行任务，失败时没有用完尝试次数的按 Backoff 或者 RetryInterval 延迟重新入队，否则记录到死信表
+func (worker *Worker) process(msg contracts.Msg) {
+	var job = msg.Job
+	defer msg.Ack()
+
+	// 之前的尝试没有正常结束，例如 worker 中途退出后被 database 驱动重新取出的任务
+	if err := worker.exhausted(job, time.Now()); err != nil {
+		job.Fail(err)
+		worker.bury(job, err.Error())
+		worker.handler.Handle(&queue.JobException{Err: err})
+		return
+	}
+
+	job.IncrementAttemptsNum()
+	exception, err := worker.handle(job)
+	if err == nil {
+		logs.Default().WithField("job", job.Uuid()).Debug("queue.Worker: job processed")
+		return
+	}
+
+	var release *released
+	if errors.As(err, &release) {
+		worker.release(job, release.delay)
+		return
+	}
+
+	job.Fail(err)
+	retryAt := time.Now().Add(worker.backoff(job))
+	if worker.exhausted(job, retryAt) != nil {
+		worker.bury(job, exception)
+	} else if err = worker.queue.Later(retryAt, job, job.GetQueue()); err != nil {
+		logs.WithError(err).WithField("job", job.Uuid()).Error("queue.Worker: release failed")
+	}
+	worker.handler.Handle(&queue.JobException{Err: errors.New(exception)})
+}
+
+// handle 按顺序经过任务声明的中间件后执行 run，中间件返回的错误优先
+func (worker *Worker) handle(job contracts.Job) (exception string, err error) {
+	var next = func() error {
+		var finished <-chan struct{}
+		exception, finished, err = worker.run(job)
+		if errors.Is(err, JobTimeoutErr) {
+			logs.WithError(err).WithField("job", job.Uuid()).Warn("queue.Worker: waiting for the timed out job to return")
+			<-finished
+		}
+		return err
+	}
+	if declared, isDeclared := job.(HasMiddleware); isDeclared {
+		middleware := declared.Middleware()
+		for i := len(middleware) - 1; i >= 0; i-- {
+			current, inner := middleware[i], next
+			next = func() error {
+				return current.Handle(worker.app, job, inner)
+			}
+		}
+	}
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = exceptions.WithRecover(recovered)
+			exception = fmt.Sprintf("%s\n\n%s", err.Error(), debug.Stack())
+		}
+	}()
+
+	result := next()
+	if result == nil {
+		return "", nil
+	}
+	if result != err {
+		return result.Error(), result
+	}
+	return exception, err
+}
+
+// run 执行 Handle，返回的 exception 为错误信息和 panic 时的调用栈
+// 超时时不等待 Handle 返回，finished 在 Handle 真正返回后关闭
+func (worker *Worker) run(job contracts.Job) (exception string, finished <-chan struct{}, err error) {
+	type result struct {
+		err       error
+		exception string
+	}
+	var (
+		done   = make(chan result, 1)
+		closed = make(chan struct{})
+	)
+	go func() {
+		defer close(closed)
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				err := exceptions.WithRecover(recovered)
+				done <- result{err: err, exception: fmt.Sprintf("%s\n\n%s", err.Error(), debug.Stack())}
+			}
+		}()
+		job.Handle()
+		done <- result{}
+	}()
+
+	var timeout = worker.options.Timeout
+	if job.GetTimeout() > 0 {
+		timeout = time.Duration(job.GetTimeout()) * time.Second
+	}
+	if timeout <= 0 {
+		res := <-done
+		return res.exception, closed, res.err
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case res := <-done:
+		return res.exception, closed, res.err
+	case <-timer.C:
+		err = fmt.Errorf("%w：%s", JobTimeoutErr, timeout)
+		return err.Error(), closed, err
+	}
+}
+
+// bury 记录到死信表，失败时推到 deaded_ 前缀的队列中，避免丢失任务
+func (worker *Worker) bury(job contracts.Job, exception string) {
+	var connection = worker.queue.GetConnectionName()
+	if worker.failed != nil {
+		err := worker.failed.Log(connection, job.GetQueue(), worker.serializer.Serializer(job), exception)
+		if err == nil {
+			return
+		}
+		logs.WithError(err).WithField("job", job.Uuid()).Warn("queue.Worker: failed to save failed job")
+	}
+	if err := worker.queue.Push(job, "deaded_"+job.GetQueue()); err != nil {
+		logs.WithError(err).WithField("job", job.Uuid()).Error("queue.Worker: failed to bury job")
+	}
+}
+
+// release 中间件放回队列的任务，这次执行不计入尝试次数
+func (worker *Worker) release(job contracts.Job, delay time.Duration) {
+	if base := baseJob(job); base != nil && base.Tries > 0 {
+		base.Tries--
+	}
+	if err := worker.queue.Later(time.Now().Add(delay), job, job.GetQueue()); err != nil {
+		logs.WithError(err).WithField("job", job.Uuid()).Error("queue.Worker: release failed")
+		return
+	}
+	logs.Default().WithField("job", job.Uuid()).Debug(fmt.Sprintf("queue.Worker: job released for %s", delay))
+}
+
+// exhausted 任务在 at 时是否还能执行，设置了 RetryUntil 时只看截止时间，否则看尝试次数
+func (worker *Worker) exhausted(job contracts.Job, at time.Time) error {
+	if declared, isDeclared := job.(HasRetryUntil); isDeclared {
+		if until := declared.RetryUntil(); !until.IsZero() {
+			if at.After(until) {
+				return fmt.Errorf("%w：%s", RetryUntilExpiredErr, until.Format(time.RFC3339))
+			}
+			return nil
+		}
+	}
+	if job.GetAttemptsNum() >= worker.maxTries(job) {
+		return fmt.Errorf("%w：%d", MaxAttemptsExceededErr, job.GetAttemptsNum())
+	}
+	return nil
+}
+
+// backoff 任务声明的 Backoff 优先，否则使用 RetryInterval 秒
+func (worker *Worker) backoff(job contracts.Job) time.Duration {
+	if declared, isDeclared := job.(HasBackoff); isDeclared {
+		if backoff := declared.Backoff(); backoff != nil {
+			return backoff(job.GetAttemptsNum())
+		}
+	}
+	return time.Duration(job.GetRetryInterval()) * time.Second
+}
+
+// maxTries 任务设置的 MaxTries 优先，都没有设置时只尝试一次
+func (worker *Worker) maxTries(job contracts.Job) int {
+	if job.GetMaxTries() > 0 {
+		return job.GetMaxTries()
+	}
+	if worker.options.Tries > 0 {
+		return worker.options.Tries
+	}
+	return 1
+}
+
+// CheckWorkers 检查至少有一个 worker 并且每个 worker 都有要消费的队列，错误中带上环境和连接名
+func CheckWorkers(workers []WorkerOptions, env string) error {
+	if len(workers) == 0 {
+		return fmt.Errorf("%w：config/queue.go 中没有 %s 环境的 Workers，可以用 queue:work --connection= --queue= 指定", NoWorkersErr, env)
+	}
+	for _, worker := range workers {
+		if len(worker.Queues) == 0 {
+			return fmt.Errorf("%w：%s 环境的 worker %s（连接 %s）没有配置队列", NoWorkersErr, env, worker.Name, worker.Connection)
+		}
+	}
+	return nil
+}
+
+// ConfigWorkers config/queue.go 中给定环境的 worker 组
+func ConfigWorkers(config queue.Config, env string) []WorkerOptions {
+	var workers = make([]WorkerOptions, 0)
+	for name, group := range config.Workers[env] {
+		workers = append(workers, WorkerOptions{
+			Name:       name,
+			Connection: group.Connection,
+			Queues:     group.Queue,
+			Processes:  group.Processes,
+			Tries:      group.Tries,
+			Timeout:    time.Duration(group.Timeout) * time.Second,
+			Sleep:      DefaultSleep,
+		})
+	}
+	return workers
+}
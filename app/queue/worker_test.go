@@ -0,0 +1,74 @@
+package queue
+
+import (
+	"errors"
+	"github.com/goal-web/contracts"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// emptyQueue 永远为空的 Poller，记录被轮询的次数
+type emptyQueue struct {
+	contracts.Queue
+	pops atomic.Int64
+}
+
+func (queue *emptyQueue) Pop(string) (*contracts.Msg, error) {
+	queue.pops.Add(1)
+	return nil, nil
+}
+
+func (queue *emptyQueue) GetConnectionName() string {
+	return "empty"
+}
+
+func TestIdleWorkerSleeps(t *testing.T) {
+	cases := []struct {
+		name  string
+		sleep time.Duration
+		max   int64
+	}{
+		{"zero sleep is raised to MinSleep", 0, 5},
+		{"negative sleep is raised to MinSleep", -time.Second, 5},
+		{"sleep longer than MinSleep is kept", 200 * time.Millisecond, 3},
+	}
+	for _, item := range cases {
+		t.Run(item.name, func(t *testing.T) {
+			var (
+				queue  = &emptyQueue{}
+				worker = NewWorker(nil, queue, nil, nil, nil, WorkerOptions{
+					Name:    "test",
+					Queues:  []string{"default"},
+					Sleep:   item.sleep,
+					MaxTime: 350 * time.Millisecond,
+				})
+			)
+			if err := worker.Work(); err != nil {
+				t.Fatal(err)
+			}
+			if pops := queue.pops.Load(); pops < 1 || pops > item.max {
+				t.Errorf("polled %d times in 350ms, want 1 to %d", pops, item.max)
+			}
+		})
+	}
+}
+
+func TestCheckWorkers(t *testing.T) {
+	cases := []struct {
+		name    string
+		workers []WorkerOptions
+		err     error
+	}{
+		{"no workers", nil, NoWorkersErr},
+		{"worker without queues", []WorkerOptions{{Name: "default", Connection: "redis"}}, NoWorkersErr},
+		{"workers with queues", []WorkerOptions{{Name: "default", Connection: "redis", Queues: []string{"default"}}}, nil},
+	}
+	for _, item := range cases {
+		t.Run(item.name, func(t *testing.T) {
+			if err := CheckWorkers(item.workers, "production"); !errors.Is(err, item.err) {
+				t.Errorf("err = %v, want %v", err, item.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package scheduling
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"github.com/goal-web/console/scheduling"
+	"github.com/goal-web/contracts"
+	"time"
+)
+
+// Event 调度事件，频率和过滤条件沿用框架的实现，互斥锁由调度器通过 app/locks 获取
+type Event struct {
+	*scheduling.Event
+	description        string
+	command            string
+	timezone           string
+	mutexName          string
+	withoutOverlapping bool
+	onOneServer        bool
+	expiresAt          time.Duration
+	onSuccess          []func(result Result)
+	onFailure          []func(result Result)
+	mailOnFailure      []string
+}
+
+// Entry 调度事件的频率方法返回 contracts.ScheduleEvent，通过 Entry 取回 *Event 以便添加钩子，例如
+// scheduling.Entry(schedule.Call(callback).Daily()).OnFailure(...)
+func Entry(event contracts.ScheduleEvent) *Event {
+	return event.(interface{ scheduled() *Event }).scheduled()
+}
+
+// NewEvent 框架事件的互斥锁不会被用到，传入空的 Mutex
+func NewEvent(callback any, timezone string) *Event {
+	return &Event{
+		Event:    scheduling.NewEvent(scheduling.NewMutex(nil), callback, timezone),
+		timezone: timezone,
+	}
+}
+
+func (event *Event) scheduled() *Event {
+	return event
+}
+
+// Timezone 计算到期时间使用的时区
+func (event *Event) Timezone(timezone string) contracts.ScheduleEvent {
+	event.Event.Timezone(timezone)
+	event.timezone = timezone
+	return event
+}
+
+// WithoutOverlapping 上一次执行还没结束时跳过本次执行，expiresAt 为锁的过期秒数，为 0 时使用 locks.DefaultTTL
+func (event *Event) WithoutOverlapping(expiresAt int) contracts.ScheduleEvent {
+	event.withoutOverlapping = true
+	event.expiresAt = time.Duration(expiresAt) * time.Second
+	return event
+}
+
+// OnOneServer 多个调度实例中，每次到期只有最先拿到锁的实例执行
+func (event *Event) OnOneServer() contracts.ScheduleEvent {
+	event.onOneServer = true
+	return event
+}
+
+// MutexName 锁名，默认由表达式和任务名称生成
+func (event *Event) MutexName() string {
+	if event.mutexName != "" {
+		return event.mutexName
+	}
+	sum := md5.Sum([]byte(event.Expression() + event.description + event.command))
+	return "goal.schedule-" + hex.EncodeToString(sum[:])
+}
+
+func (event *Event) SetMutexName(mutexName string) contracts.ScheduleEvent {
+	event.mutexName = mutexName
+	return event
+}
+
+// OnSuccess 执行成功后调用
+func (event *Event) OnSuccess(callback func(result Result)) *Event {
+	event.onSuccess = append(event.onSuccess, callback)
+	return event
+}
+
+// OnFailure 执行返回错误或者 panic 后调用，跳过的执行不会触发
+func (event *Event) OnFailure(callback func(result Result)) *Event {
+	event.onFailure = append(event.onFailure, callback)
+	return event
+}
+
+// EmailOutputOnFailure 执行失败时通过默认邮件服务把错误信息发送给给定的地址
+func (event *Event) EmailOutputOnFailure(addresses ...string) *Event {
+	event.mailOnFailure = append(event.mailOnFailure, addresses...)
+	return event
+}
+
+// CallbackEvent Schedule.Call 注册的事件
+type CallbackEvent struct {
+	*Event
+}
+
+// Description 任务名称，用于 schedule:list、schedule:test
+func (event *CallbackEvent) Description(description string) contracts.CallbackEvent {
+	event.description = description
+	return event
+}
+
+// CommandEvent Schedule.Command、Schedule.Exec 注册的事件
+type CommandEvent struct {
+	*Event
+}
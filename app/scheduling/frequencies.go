@@ -0,0 +1,268 @@
+package scheduling
+
+import (
+	"github.com/goal-web/contracts"
+	"time"
+)
+
+// 以下方法委托给框架的调度事件，返回值换成 *Event，使链式调用之后仍然可以使用 WithoutOverlapping、OnOneServer
+
+func (event *Event) Skip(callback func() bool) contracts.ScheduleEvent {
+	event.Event.Skip(callback)
+	return event
+}
+
+func (event *Event) When(callback func() bool) contracts.ScheduleEvent {
+	event.Event.When(callback)
+	return event
+}
+
+func (event *Event) SpliceIntoPosition(position int, value string) contracts.ScheduleEvent {
+	event.Event.SpliceIntoPosition(position, value)
+	return event
+}
+
+func (event *Event) Cron(expression string) contracts.ScheduleEvent {
+	event.Event.Cron(expression)
+	return event
+}
+
+func (event *Event) Days(day string, days ...string) contracts.ScheduleEvent {
+	event.Event.Days(day, days...)
+	return event
+}
+
+func (event *Event) Years(years ...string) contracts.ScheduleEvent {
+	event.Event.Years(years...)
+	return event
+}
+
+func (event *Event) Yearly() contracts.ScheduleEvent {
+	event.Event.Yearly()
+	return event
+}
+
+func (event *Event) YearlyOn(month time.Month, dayOfMonth int, time string) contracts.ScheduleEvent {
+	event.Event.YearlyOn(month, dayOfMonth, time)
+	return event
+}
+
+func (event *Event) Quarterly() contracts.ScheduleEvent {
+	event.Event.Quarterly()
+	return event
+}
+
+func (event *Event) LastDayOfMonth(time string) contracts.ScheduleEvent {
+	event.Event.LastDayOfMonth(time)
+	return event
+}
+
+func (event *Event) TwiceMonthly(first, second int, time string) contracts.ScheduleEvent {
+	event.Event.TwiceMonthly(first, second, time)
+	return event
+}
+
+func (event *Event) Monthly() contracts.ScheduleEvent {
+	event.Event.Monthly()
+	return event
+}
+
+func (event *Event) MonthlyOn(dayOfMonth int, time string) contracts.ScheduleEvent {
+	event.Event.MonthlyOn(dayOfMonth, time)
+	return event
+}
+
+func (event *Event) WeeklyOn(dayOfWeek time.Weekday, time string) contracts.ScheduleEvent {
+	event.Event.WeeklyOn(dayOfWeek, time)
+	return event
+}
+
+func (event *Event) Weekly() contracts.ScheduleEvent {
+	event.Event.Weekly()
+	return event
+}
+
+func (event *Event) Sundays() contracts.ScheduleEvent {
+	event.Event.Sundays()
+	return event
+}
+
+func (event *Event) Saturdays() contracts.ScheduleEvent {
+	event.Event.Saturdays()
+	return event
+}
+
+func (event *Event) Fridays() contracts.ScheduleEvent {
+	event.Event.Fridays()
+	return event
+}
+
+func (event *Event) Thursdays() contracts.ScheduleEvent {
+	event.Event.Thursdays()
+	return event
+}
+
+func (event *Event) Wednesdays() contracts.ScheduleEvent {
+	event.Event.Wednesdays()
+	return event
+}
+
+func (event *Event) Tuesdays() contracts.ScheduleEvent {
+	event.Event.Tuesdays()
+	return event
+}
+
+func (event *Event) Mondays() contracts.ScheduleEvent {
+	event.Event.Mondays()
+	return event
+}
+
+func (event *Event) Weekends() contracts.ScheduleEvent {
+	event.Event.Weekends()
+	return event
+}
+
+func (event *Event) Weekdays() contracts.ScheduleEvent {
+	event.Event.Weekdays()
+	return event
+}
+
+func (event *Event) TwiceDailyAt(first, second, offset int) contracts.ScheduleEvent {
+	event.Event.TwiceDailyAt(first, second, offset)
+	return event
+}
+
+func (event *Event) TwiceDaily(first, second int) contracts.ScheduleEvent {
+	event.Event.TwiceDaily(first, second)
+	return event
+}
+
+func (event *Event) DailyAt(time string) contracts.ScheduleEvent {
+	event.Event.DailyAt(time)
+	return event
+}
+
+func (event *Event) Daily() contracts.ScheduleEvent {
+	event.Event.Daily()
+	return event
+}
+
+func (event *Event) EverySixHours() contracts.ScheduleEvent {
+	event.Event.EverySixHours()
+	return event
+}
+
+func (event *Event) EveryFourHours() contracts.ScheduleEvent {
+	event.Event.EveryFourHours()
+	return event
+}
+
+func (event *Event) EveryThreeHours() contracts.ScheduleEvent {
+	event.Event.EveryThreeHours()
+	return event
+}
+
+func (event *Event) EveryTwoHours() contracts.ScheduleEvent {
+	event.Event.EveryTwoHours()
+	return event
+}
+
+func (event *Event) HourlyAt(offset ...int) contracts.ScheduleEvent {
+	event.Event.HourlyAt(offset...)
+	return event
+}
+
+func (event *Event) Hourly() contracts.ScheduleEvent {
+	event.Event.Hourly()
+	return event
+}
+
+func (event *Event) EveryThirtyMinutes() contracts.ScheduleEvent {
+	event.Event.EveryThirtyMinutes()
+	return event
+}
+
+func (event *Event) EveryFifteenMinutes() contracts.ScheduleEvent {
+	event.Event.EveryFifteenMinutes()
+	return event
+}
+
+func (event *Event) EveryTenMinutes() contracts.ScheduleEvent {
+	event.Event.EveryTenMinutes()
+	return event
+}
+
+func (event *Event) EveryFiveMinutes() contracts.ScheduleEvent {
+	event.Event.EveryFiveMinutes()
+	return event
+}
+
+func (event *Event) EveryFourMinutes() contracts.ScheduleEvent {
+	event.Event.EveryFourMinutes()
+	return event
+}
+
+func (event *Event) EveryThreeMinutes() contracts.ScheduleEvent {
+	event.Event.EveryThreeMinutes()
+	return event
+}
+
+func (event *Event) EveryTwoMinutes() contracts.ScheduleEvent {
+	event.Event.EveryTwoMinutes()
+	return event
+}
+
+func (event *Event) EveryMinute() contracts.ScheduleEvent {
+	event.Event.EveryMinute()
+	return event
+}
+
+func (event *Event) UnlessBetween(startTime, endTime string) contracts.ScheduleEvent {
+	event.Event.UnlessBetween(startTime, endTime)
+	return event
+}
+
+func (event *Event) Between(startTime, endTime string) contracts.ScheduleEvent {
+	event.Event.Between(startTime, endTime)
+	return event
+}
+
+func (event *Event) EveryThirtySeconds() contracts.ScheduleEvent {
+	event.Event.EveryThirtySeconds()
+	return event
+}
+
+func (event *Event) EveryFifteenSeconds() contracts.ScheduleEvent {
+	event.Event.EveryFifteenSeconds()
+	return event
+}
+
+func (event *Event) EveryTenSeconds() contracts.ScheduleEvent {
+	event.Event.EveryTenSeconds()
+	return event
+}
+
+func (event *Event) EveryFiveSeconds() contracts.ScheduleEvent {
+	event.Event.EveryFiveSeconds()
+	return event
+}
+
+func (event *Event) EveryFourSeconds() contracts.ScheduleEvent {
+	event.Event.EveryFourSeconds()
+	return event
+}
+
+func (event *Event) EveryThreeSeconds() contracts.ScheduleEvent {
+	event.Event.EveryThreeSeconds()
+	return event
+}
+
+func (event *Event) EveryTwoSeconds() contracts.ScheduleEvent {
+	event.Event.EveryTwoSeconds()
+	return event
+}
+
+func (event *Event) EverySecond() contracts.ScheduleEvent {
+	event.Event.EverySecond()
+	return event
+}
@@ -0,0 +1,113 @@
+package scheduling
+
+import (
+	"fmt"
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/goal/app/migration"
+	"strings"
+	"time"
+)
+
+// HistoryTable 执行历史表，见 database/migrations
+const HistoryTable = "schedule_runs"
+
+// 执行结果
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailed  = "failed"
+)
+
+// Run 一条执行记录
+type Run struct {
+	ID         int64
+	Task       string
+	Expression string
+	StartedAt  time.Time
+	Duration   time.Duration
+	Outcome    string
+	Message    string
+}
+
+// Failed 是否失败
+func (run Run) Failed() bool {
+	return run.Outcome == OutcomeFailed
+}
+
+// History 把每次执行记录到 schedule_runs 表，过滤条件未通过或者没拿到锁而跳过的不记录
+type History struct {
+	connection contracts.DBConnection
+}
+
+func NewHistory(connection contracts.DBConnection) *History {
+	return &History{connection: connection}
+}
+
+// Record 记录一次执行，失败时 message 为错误或者 panic 的信息
+func (history *History) Record(result Result) error {
+	var (
+		outcome = OutcomeSuccess
+		message any
+	)
+	if result.Err != nil {
+		outcome, message = OutcomeFailed, result.Err.Error()
+	}
+	_, err := history.connection.Exec(
+		fmt.Sprintf("insert into %s (task, expression, started_at, duration, outcome, message) values (?, ?, ?, ?, ?, ?)", HistoryTable),
+		result.Task.Name(), result.Task.Expression(), result.StartedAt.UTC(), result.Duration.Milliseconds(), outcome, message,
+	)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// record 查询结果，时间在各数据库驱动中返回的类型不同，统一读取为字符串
+type record struct {
+	ID         int64   `db:"id"`
+	Task       string  `db:"task"`
+	Expression string  `db:"expression"`
+	StartedAt  string  `db:"started_at"`
+	Duration   int64   `db:"duration"`
+	Outcome    string  `db:"outcome"`
+	Message    *string `db:"message"`
+}
+
+// Recent 最近的执行记录，task、outcome 为空时不过滤，按开始时间倒序
+func (history *History) Recent(task, outcome string, limit int) ([]Run, error) {
+	var (
+		conditions = make([]string, 0)
+		args       = make([]any, 0)
+		records    = make([]record, 0)
+	)
+	if task != "" {
+		conditions, args = append(conditions, "task = ?"), append(args, task)
+	}
+	if outcome != "" {
+		conditions, args = append(conditions, "outcome = ?"), append(args, outcome)
+	}
+	query := fmt.Sprintf("select id, task, expression, started_at, duration, outcome, message from %s", HistoryTable)
+	if len(conditions) > 0 {
+		query += " where " + strings.Join(conditions, " and ")
+	}
+	query += fmt.Sprintf(" order by started_at desc, id desc limit %d", limit)
+	if err := history.connection.Select(&records, query, args...); err != nil {
+		return nil, err
+	}
+
+	var runs = make([]Run, 0, len(records))
+	for _, item := range records {
+		run := Run{
+			ID:         item.ID,
+			Task:       item.Task,
+			Expression: item.Expression,
+			StartedAt:  migration.ParseTime(item.StartedAt),
+			Duration:   time.Duration(item.Duration) * time.Millisecond,
+			Outcome:    item.Outcome,
+		}
+		if item.Message != nil {
+			run.Message = *item.Message
+		}
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
@@ -0,0 +1,175 @@
+package scheduling
+
+import (
+	"github.com/alicebob/miniredis/v2"
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/database/drivers"
+	"github.com/goal-web/goal/app/migration"
+	"github.com/goal-web/goal/database/migrations"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newHistorySchedule 执行历史记录到临时 sqlite 中的调度，表结构来自 database/migrations
+func newHistorySchedule(t *testing.T) (*Schedule, contracts.Application, *History) {
+	t.Helper()
+	var conn = drivers.SqliteConnector(contracts.Fields{"database": filepath.Join(t.TempDir(), "test.db")}, nil)
+	migrator, err := migration.NewMigrator(conn, migrations.All())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = migrator.Run(false); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		app      = newApp(miniredis.RunT(t))
+		schedule = NewSchedule(app)
+		history  = NewHistory(conn)
+	)
+	schedule.historyOnce.Do(func() {
+		schedule.history = history
+	})
+	return schedule, app, history
+}
+
+// sentMails 记录发送的邮件
+type sentMails struct {
+	contracts.Mailer
+	mails []contracts.Mailable
+}
+
+func (mailer *sentMails) Send(mail contracts.Mailable) error {
+	mailer.mails = append(mailer.mails, mail)
+	return nil
+}
+
+func TestHistory(t *testing.T) {
+	var schedule, app, history = newHistorySchedule(t)
+	schedule.Call(func() {}).Description("report").EveryMinute()
+	schedule.Call(func() { panic("boom") }).Description("broken").Hourly()
+	schedule.Call(func() {}).Description("skipped").When(func() bool { return false })
+	tasks := Tasks(schedule)
+
+	for _, task := range []Task{tasks[0], tasks[1], tasks[2], tasks[0]} {
+		task.Run(app, time.Time{})
+	}
+
+	cases := []struct {
+		name     string
+		task     string
+		outcome  string
+		limit    int
+		expected []string
+	}{
+		{"latest first", "", "", 10, []string{"report", "broken", "report"}},
+		{"limit", "", "", 1, []string{"report"}},
+		{"by task", "broken", "", 10, []string{"broken"}},
+		{"by outcome", "", OutcomeSuccess, 10, []string{"report", "report"}},
+		{"by task and outcome", "report", OutcomeFailed, 10, []string{}},
+	}
+	for _, item := range cases {
+		t.Run(item.name, func(t *testing.T) {
+			runs, err := history.Recent(item.task, item.outcome, item.limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names = make([]string, 0, len(runs))
+			for _, run := range runs {
+				names = append(names, run.Task)
+			}
+			if !reflect.DeepEqual(names, item.expected) {
+				t.Errorf("runs = %v, want %v", names, item.expected)
+			}
+		})
+	}
+
+	t.Run("failed runs keep the message", func(t *testing.T) {
+		runs, _ := history.Recent("broken", "", 1)
+		if len(runs) != 1 {
+			t.Fatalf("runs = %v, want one run", runs)
+		}
+		var run = runs[0]
+		if !run.Failed() || !strings.Contains(run.Message, "boom") {
+			t.Errorf("outcome = %q, message = %q, want a failed run with the panic", run.Outcome, run.Message)
+		}
+		if run.Expression != tasks[1].Expression() {
+			t.Errorf("expression = %q, want %q", run.Expression, tasks[1].Expression())
+		}
+		if run.StartedAt.IsZero() || time.Since(run.StartedAt) > time.Minute {
+			t.Errorf("started at %v", run.StartedAt)
+		}
+	})
+
+	t.Run("successful runs have no message", func(t *testing.T) {
+		runs, _ := history.Recent("report", "", 1)
+		if len(runs) != 1 || runs[0].Failed() || runs[0].Message != "" {
+			t.Errorf("runs = %+v, want one successful run without message", runs)
+		}
+	})
+}
+
+func TestHooks(t *testing.T) {
+	var (
+		schedule, app, _ = newHistorySchedule(t)
+		mailer           = &sentMails{}
+		called           = make([]string, 0)
+		hook             = func(name string) func(Result) {
+			return func(Result) { called = append(called, name) }
+		}
+	)
+	app.Singleton("mailer", func() contracts.Mailer { return mailer })
+
+	Entry(schedule.Call(func() {}).Description("succeeds")).
+		OnSuccess(hook("success")).
+		OnFailure(hook("failure")).
+		EmailOutputOnFailure("ops@example.com")
+	Entry(schedule.Call(func() { panic("broken") }).Description("fails")).
+		OnSuccess(hook("success")).
+		OnFailure(func(Result) { panic("hook") }).
+		OnFailure(hook("failure")).
+		EmailOutputOnFailure("ops@example.com", "dev@example.com")
+	Entry(schedule.Call(func() {}).Description("skipped").When(func() bool { return false })).
+		OnSuccess(hook("success")).
+		OnFailure(hook("failure")).
+		EmailOutputOnFailure("ops@example.com")
+	tasks := Tasks(schedule)
+
+	cases := []struct {
+		name   string
+		task   Task
+		called []string
+		mails  int
+	}{
+		{"success", tasks[0], []string{"success"}, 0},
+		{"failure after a panicking hook", tasks[1], []string{"failure"}, 1},
+		{"skipped runs trigger no hooks", tasks[2], []string{}, 0},
+	}
+	for _, item := range cases {
+		t.Run(item.name, func(t *testing.T) {
+			called, mailer.mails = called[:0], nil
+			item.task.Run(app, time.Time{})
+			if !reflect.DeepEqual(called, item.called) {
+				t.Errorf("hooks = %v, want %v", called, item.called)
+			}
+			if len(mailer.mails) != item.mails {
+				t.Fatalf("sent %d mails, want %d", len(mailer.mails), item.mails)
+			}
+		})
+	}
+
+	t.Run("failure mail", func(t *testing.T) {
+		mailer.mails = nil
+		tasks[1].Run(app, time.Time{})
+		var mail = mailer.mails[0]
+		if !reflect.DeepEqual(mail.GetTo(), []string{"ops@example.com", "dev@example.com"}) {
+			t.Errorf("to = %v", mail.GetTo())
+		}
+		if !strings.Contains(mail.GetSubject(), "fails") || !strings.Contains(mail.GetText(), "broken") {
+			t.Errorf("subject = %q, text = %q", mail.GetSubject(), mail.GetText())
+		}
+	})
+}
@@ -0,0 +1,29 @@
+package scheduling
+
+import (
+	"fmt"
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/email"
+	"github.com/goal-web/supports/logs"
+	"strings"
+	"time"
+)
+
+// mailFailure 通过默认邮件服务发送失败通知，发送失败只记录日志
+func mailFailure(app contracts.Application, addresses []string, result Result) {
+	var (
+		subject = fmt.Sprintf("调度任务执行失败：%s", result.Task.Name())
+		lines   = []string{
+			"任务：" + result.Task.Name(),
+			"表达式：" + result.Task.Expression(),
+			"开始时间：" + result.StartedAt.Format("2006-01-02 15:04:05 -07:00"),
+			"耗时：" + result.Duration.Round(time.Millisecond).String(),
+			"",
+			result.Err.Error(),
+		}
+	)
+	mail := email.New(subject, email.Text(strings.Join(lines, "\n"))).SetTo(addresses...)
+	if err := app.Get("mailer").(contracts.Mailer).Send(mail); err != nil {
+		logs.WithError(err).WithField("task", result.Task.Name()).Error("scheduling.Task: send failure mail failed")
+	}
+}
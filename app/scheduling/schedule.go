@@ -0,0 +1,151 @@
+package scheduling
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/goal-web/application"
+	"github.com/goal-web/console/inputs"
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/database"
+	"github.com/goal-web/goal/app/dbcheck"
+	"github.com/goal-web/goal/app/locks"
+	"github.com/goal-web/goal/config"
+	"github.com/goal-web/supports/logs"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+var (
+	ExecFailedErr         = errors.New("外部程序执行失败")
+	HistoryUnavailableErr = errors.New("执行历史的数据库不可用")
+)
+
+// Schedule 与框架的 Schedule 用法相同，事件为 *Event，锁存储由 config/scheduling.go 配置
+type Schedule struct {
+	app      contracts.Application
+	timezone string
+	store    string
+	events   []contracts.ScheduleEvent
+
+	history     *History
+	historyErr  error
+	historyOnce sync.Once
+}
+
+func NewSchedule(app contracts.Application) *Schedule {
+	return &Schedule{
+		app:      app,
+		timezone: app.Get("config").(contracts.Config).Get("app").(application.Config).Timezone,
+		events:   make([]contracts.ScheduleEvent, 0),
+	}
+}
+
+// UseStore 覆盖配置中的锁存储，redis 或者 cache
+func (schedule *Schedule) UseStore(store string) {
+	schedule.store = store
+}
+
+// Locks 任务加锁使用的存储
+func (schedule *Schedule) Locks() (locks.Store, error) {
+	conf := schedule.config().Locks
+	if schedule.store != "" {
+		conf.Store = schedule.store
+	}
+	return locks.Resolve(schedule.app, conf.Store, conf.Connection, conf.Prefix)
+}
+
+// History 任务的执行历史，连接只在第一次调用时解析，调度启动时调用一次
+// 解析前先直接 ping 数据库，框架的数据库驱动连接失败时会直接退出进程，不可用时本进程不再记录历史
+func (schedule *Schedule) History() (*History, error) {
+	schedule.historyOnce.Do(func() {
+		if schedule.history, schedule.historyErr = schedule.resolveHistory(); schedule.historyErr != nil {
+			logs.WithError(schedule.historyErr).Warn("scheduling.Schedule: history disabled")
+		}
+	})
+	return schedule.history, schedule.historyErr
+}
+
+func (schedule *Schedule) resolveHistory() (history *History, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("%w：%v", HistoryUnavailableErr, recovered)
+		}
+	}()
+	var (
+		name      = schedule.config().HistoryConnection
+		databases = schedule.app.Get("config").(contracts.Config).Get("database").(database.Config)
+	)
+	if name == "" {
+		name = databases.Default
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err = dbcheck.Ping(ctx, databases.Connections[name]); err != nil {
+		return nil, fmt.Errorf("%w：%s：%v", HistoryUnavailableErr, name, err)
+	}
+	return NewHistory(schedule.app.Get("db.factory").(contracts.DBFactory).Connection(name)), nil
+}
+
+// record 记录一次执行，失败时只记录日志，不影响任务的结果和钩子
+func (schedule *Schedule) record(result Result) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			logs.WithField("task", result.Task.Name()).WithField("panic", recovered).Warn("scheduling.Schedule: record history failed")
+		}
+	}()
+	history, err := schedule.History()
+	if err != nil {
+		return
+	}
+	if err = history.Record(result); err != nil {
+		logs.WithError(err).WithField("task", result.Task.Name()).Warn("scheduling.Schedule: record history failed")
+	}
+}
+
+func (schedule *Schedule) config() config.SchedulingConfig {
+	return schedule.app.Get("config").(contracts.Config).Get("scheduling").(config.SchedulingConfig)
+}
+
+func (schedule *Schedule) GetEvents() []contracts.ScheduleEvent {
+	return schedule.events
+}
+
+func (schedule *Schedule) Call(callback any, args ...any) contracts.CallbackEvent {
+	event := &CallbackEvent{NewEvent(func() {
+		schedule.app.Call(callback, args...)
+	}, schedule.timezone)}
+	schedule.events = append(schedule.events, event)
+	return event
+}
+
+func (schedule *Schedule) Command(command contracts.Command, args ...string) contracts.CommandEvent {
+	args = append([]string{command.GetName()}, args...)
+	input := inputs.String(args...)
+	if err := command.InjectArguments(input.GetArguments()); err != nil {
+		logs.WithError(err).WithField("args", args).Debug("Schedule.Command: arguments invalid")
+		panic(err) // 这个阶段应用还没有启动，参数有误直接 panic
+	}
+	event := &CommandEvent{NewEvent(func() {
+		command.Handle()
+	}, schedule.timezone)}
+	event.command = command.GetName()
+	schedule.events = append(schedule.events, event)
+	return event
+}
+
+// Exec 执行 console 中的命令，不存在时作为外部程序执行
+func (schedule *Schedule) Exec(command string, args ...string) contracts.CommandEvent {
+	event := &CommandEvent{NewEvent(func(console contracts.Console) {
+		if console.Exists(command) {
+			input := inputs.String(append([]string{command}, args...)...)
+			console.Run(&input)
+		} else if output, err := exec.Command(command, args...).CombinedOutput(); err != nil {
+			panic(fmt.Errorf("%w：%s：%v\n%s", ExecFailedErr, command, err, output))
+		}
+	}, schedule.timezone)}
+	event.command = command
+	schedule.events = append(schedule.events, event)
+	return event
+}
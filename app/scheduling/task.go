@@ -0,0 +1,237 @@
+package scheduling
+
+import (
+	"errors"
+	"fmt"
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/goal/app/locks"
+	"github.com/goal-web/supports/logs"
+	"github.com/gorhill/cronexpr"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	TaskNotFoundErr      = errors.New("调度任务不存在")
+	InvalidExpressionErr = errors.New("调度任务的 cron 表达式无效")
+	TaskSkippedErr       = errors.New("调度任务的过滤条件未通过")
+	TaskOverlappingErr   = errors.New("调度任务的上一次执行还没有结束")
+	TaskOnOtherServerErr = errors.New("调度任务已经在其他服务器上执行")
+	TaskPanicErr         = errors.New("调度任务执行时发生 panic")
+)
+
+// Task 调度中的一个任务，Index 为在 Kernel.Schedule 中的注册顺序，从 0 开始
+type Task struct {
+	Index    int
+	Event    *Event
+	schedule *Schedule
+}
+
+// Tasks 调度中的所有任务
+func Tasks(schedule *Schedule) []Task {
+	var tasks = make([]Task, 0)
+	for index, event := range schedule.GetEvents() {
+		if scheduled, ok := event.(interface{ scheduled() *Event }); ok {
+			tasks = append(tasks, Task{Index: index, Event: scheduled.scheduled(), schedule: schedule})
+		}
+	}
+	return tasks
+}
+
+// Find 按名称或者序号查找任务
+func Find(tasks []Task, key string) (Task, error) {
+	for _, task := range tasks {
+		if task.Name() == key {
+			return task, nil
+		}
+	}
+	if index, err := strconv.Atoi(strings.TrimPrefix(key, "#")); err == nil {
+		for _, task := range tasks {
+			if task.Index == index {
+				return task, nil
+			}
+		}
+	}
+	return Task{}, fmt.Errorf("%w：%s", TaskNotFoundErr, key)
+}
+
+// Name 任务的描述，没有描述时为命令名，都没有时为 #序号
+func (task Task) Name() string {
+	for _, name := range []string{task.Event.description, task.Event.command} {
+		if name != "" {
+			return name
+		}
+	}
+	return "#" + strconv.Itoa(task.Index)
+}
+
+// Expression 7 位的 cron 表达式：秒 分 时 日 月 周 年
+func (task Task) Expression() string {
+	return task.Event.Expression()
+}
+
+// Timezone 任务的时区，未设置时为 app.timezone
+func (task Task) Timezone() string {
+	return task.Event.timezone
+}
+
+// Location 任务时区对应的 time.Location，时区为空或者无效时为本地时区
+func (task Task) Location() *time.Location {
+	if timezone := task.Timezone(); timezone != "" {
+		if location, err := time.LoadLocation(timezone); err == nil {
+			return location
+		}
+	}
+	return time.Local
+}
+
+// Next after 之后下一次执行的时间，按任务的时区计算
+func (task Task) Next(after time.Time) (time.Time, error) {
+	expression, err := cronexpr.Parse(task.Expression())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w：%s：%v", InvalidExpressionErr, task.Expression(), err)
+	}
+	return expression.Next(after.In(task.Location())), nil
+}
+
+// DueAt 任务是否应该在给定的这一秒执行
+func (task Task) DueAt(at time.Time) bool {
+	at = at.Truncate(time.Second)
+	next, err := task.Next(at.Add(-time.Second))
+	return err == nil && next.Equal(at)
+}
+
+// DueWithin 任务在 at 所在的这一分钟内是否需要执行，用于每分钟由 cron 调用一次的 schedule:run
+func (task Task) DueWithin(at time.Time) bool {
+	start := at.Truncate(time.Minute)
+	next, err := task.Next(start.Add(-time.Second))
+	return err == nil && next.Before(start.Add(time.Minute))
+}
+
+// Result 一次执行的结果
+type Result struct {
+	Task      Task
+	StartedAt time.Time
+	Duration  time.Duration
+	Err       error
+}
+
+// Skipped 是否因为过滤条件未通过、上一次执行还没结束或者已经在其他服务器上执行而没有执行
+func (result Result) Skipped() bool {
+	return errors.Is(result.Err, TaskSkippedErr) || errors.Is(result.Err, TaskOverlappingErr) || errors.Is(result.Err, TaskOnOtherServerErr)
+}
+
+// Run 执行任务，panic 会被转换为错误返回，没有跳过的执行会记录到执行历史并触发 OnSuccess、OnFailure 钩子
+// at 为本次到期的时间，同一个到期时间只有一台服务器能执行 OnOneServer 的任务，为零值时不检查，用于手动执行
+func (task Task) Run(app contracts.Application, at time.Time) Result {
+	result := task.execute(app, at)
+	if result.Skipped() {
+		return result
+	}
+	task.schedule.record(result)
+
+	var hooks = task.Event.onSuccess
+	if result.Err != nil {
+		hooks = task.Event.onFailure
+		if len(task.Event.mailOnFailure) > 0 {
+			hooks = append(append([]func(Result){}, hooks...), func(result Result) {
+				mailFailure(app, task.Event.mailOnFailure, result)
+			})
+		}
+	}
+	for _, hook := range hooks {
+		callHook(task, hook, result)
+	}
+	return result
+}
+
+// callHook 钩子 panic 时只记录日志，不影响其他钩子
+func callHook(task Task, hook func(Result), result Result) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			logs.WithField("task", task.Name()).WithField("panic", recovered).Error("scheduling.Task: hook panicked")
+		}
+	}()
+	hook(result)
+}
+
+func (task Task) execute(app contracts.Application, at time.Time) (result Result) {
+	result = Result{Task: task, StartedAt: time.Now()}
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			result.Err = fmt.Errorf("%w：%v", TaskPanicErr, recovered)
+		}
+		result.Duration = time.Since(result.StartedAt)
+	}()
+
+	if !task.Event.FiltersPass() {
+		result.Err = fmt.Errorf("%w：%s", TaskSkippedErr, task.Name())
+		return
+	}
+
+	if task.Event.onOneServer && !at.IsZero() {
+		// 这把锁不释放，让同一时间到期的其他服务器拿不到，一小时后过期
+		lock, err := task.lock(fmt.Sprintf("%s-%d", task.Event.MutexName(), at.Unix()), time.Hour)
+		if err != nil {
+			result.Err = err
+			return
+		}
+		if lock == nil {
+			result.Err = fmt.Errorf("%w：%s", TaskOnOtherServerErr, task.Name())
+			return
+		}
+	}
+
+	if task.Event.withoutOverlapping {
+		lock, err := task.lock(task.Event.MutexName(), task.Event.expiresAt)
+		if err != nil {
+			result.Err = err
+			return
+		}
+		if lock == nil {
+			result.Err = fmt.Errorf("%w：%s", TaskOverlappingErr, task.Name())
+			return
+		}
+		defer func() {
+			if err := lock.Release(); err != nil {
+				logs.WithError(err).WithField("task", task.Name()).Warn("scheduling.Task: release lock failed")
+			}
+		}()
+	}
+
+	task.Event.Run(app)
+	return
+}
+
+// lock 获取锁，已被占用时返回 nil
+func (task Task) lock(name string, ttl time.Duration) (*locks.Lock, error) {
+	store, err := task.schedule.Locks()
+	if err != nil {
+		return nil, err
+	}
+	lock := locks.New(store, name, ttl)
+	acquired, err := lock.Acquire()
+	if err != nil || !acquired {
+		return nil, err
+	}
+	return lock, nil
+}
+
+// RunAll 并发执行在 at 到期的任务，等待所有任务完成，结果与 tasks 的顺序一致
+func RunAll(app contracts.Application, tasks []Task, at time.Time) []Result {
+	var (
+		results = make([]Result, len(tasks))
+		wg      sync.WaitGroup
+	)
+	for i, task := range tasks {
+		wg.Add(1)
+		go func(i int, task Task) {
+			defer wg.Done()
+			results[i] = task.Run(app, at)
+		}(i, task)
+	}
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,273 @@
+package scheduling
+
+import (
+	"errors"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/goal-web/application"
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/goal/config"
+	"github.com/goal-web/redis"
+	"reflect"
+	"testing"
+	"time"
+	_ "time/tzdata" // 任务的时区不依赖系统的时区数据
+)
+
+// testConfig 调度需要的配置，锁使用内存 redis
+type testConfig struct {
+	contracts.Config
+	values map[string]any
+}
+
+func (conf testConfig) Get(key string) any {
+	return conf.values[key]
+}
+
+// ignoreExceptions redis 服务需要注入异常处理器
+type ignoreExceptions struct {
+	contracts.ExceptionHandler
+}
+
+func newApp(server *miniredis.Miniredis) contracts.Application {
+	app := application.New()
+	app.Singleton("config", func() contracts.Config {
+		return testConfig{values: map[string]any{
+			"app": application.Config{Timezone: "UTC"},
+			"scheduling": config.SchedulingConfig{
+				Locks: config.LocksConfig{Store: "redis", Prefix: "goal:"},
+			},
+			"redis": redis.Config{
+				Default: "default",
+				Stores:  map[string]contracts.Fields{"default": {"host": server.Host(), "port": server.Port()}},
+			},
+		}}
+	})
+	app.Singleton("exceptions.handler", func() contracts.ExceptionHandler { return ignoreExceptions{} })
+	redis.NewService().Register(app)
+	return app
+}
+
+// newSchedule 不记录执行历史的调度，同一个 server 上的调度共享锁，相当于多个调度实例
+func newSchedule(server *miniredis.Miniredis) (*Schedule, contracts.Application) {
+	var (
+		app      = newApp(server)
+		schedule = NewSchedule(app)
+	)
+	schedule.historyOnce.Do(func() {
+		schedule.historyErr = HistoryUnavailableErr
+	})
+	return schedule, app
+}
+
+func TestWithoutOverlapping(t *testing.T) {
+	var (
+		schedule, app = newSchedule(miniredis.RunT(t))
+		started       = make(chan struct{})
+		finish        = make(chan struct{})
+		runs          = 0
+	)
+	Entry(schedule.Call(func() {
+		runs++
+		if runs == 1 {
+			close(started)
+			<-finish
+		}
+	}).Description("long").EveryMinute()).WithoutOverlapping(0)
+	task := Tasks(schedule)[0]
+
+	var first = make(chan Result)
+	go func() {
+		first <- task.Run(app, time.Time{})
+	}()
+	<-started
+	if result := task.Run(app, time.Time{}); !errors.Is(result.Err, TaskOverlappingErr) || !result.Skipped() {
+		t.Errorf("overlapping run err = %v, want %v", result.Err, TaskOverlappingErr)
+	}
+	close(finish)
+	if result := <-first; result.Err != nil {
+		t.Fatal(result.Err)
+	}
+	if result := task.Run(app, time.Time{}); result.Err != nil {
+		t.Errorf("run after the previous one finished err = %v", result.Err)
+	}
+	if runs != 2 {
+		t.Errorf("runs = %d, want 2", runs)
+	}
+}
+
+func TestOnOneServer(t *testing.T) {
+	var (
+		server       = miniredis.RunT(t)
+		at           = time.Date(2023, 4, 16, 10, 0, 0, 0, time.UTC)
+		runs         = 0
+		tasks        = make([]Task, 0)
+		applications = make([]contracts.Application, 0)
+	)
+	for i := 0; i < 2; i++ {
+		schedule, app := newSchedule(server)
+		Entry(schedule.Call(func() { runs++ }).Description("report").EveryMinute()).OnOneServer()
+		tasks, applications = append(tasks, Tasks(schedule)[0]), append(applications, app)
+	}
+
+	cases := []struct {
+		name   string
+		server int
+		at     time.Time
+		err    error
+	}{
+		{"first server runs", 0, at, nil},
+		{"second server skips the same tick", 1, at, TaskOnOtherServerErr},
+		{"first server skips the same tick again", 0, at, TaskOnOtherServerErr},
+		{"second server runs the next tick", 1, at.Add(time.Minute), nil},
+		{"manual runs are not checked", 1, time.Time{}, nil},
+	}
+	for _, item := range cases {
+		t.Run(item.name, func(t *testing.T) {
+			before := runs
+			result := tasks[item.server].Run(applications[item.server], item.at)
+			if !errors.Is(result.Err, item.err) {
+				t.Fatalf("err = %v, want %v", result.Err, item.err)
+			}
+			if ran := runs > before; ran != (item.err == nil) {
+				t.Errorf("ran = %v, want %v", ran, item.err == nil)
+			}
+		})
+	}
+}
+
+func TestNext(t *testing.T) {
+	var (
+		schedule, _ = newSchedule(miniredis.RunT(t))
+		after       = time.Date(2023, 4, 16, 0, 0, 0, 0, time.UTC) // 周日
+	)
+	schedule.Call(func() {}).EveryMinute()
+	schedule.Call(func() {}).DailyAt("10:30")
+	schedule.Call(func() {}).DailyAt("10:30").Timezone("Asia/Shanghai")
+	schedule.Call(func() {}).Cron("0 0 9 * * 1 *")
+	schedule.Call(func() {}).Cron("not a cron")
+	tasks := Tasks(schedule)
+
+	cases := []struct {
+		name     string
+		task     Task
+		expected time.Time
+		err      error
+	}{
+		{"every minute", tasks[0], time.Date(2023, 4, 16, 0, 1, 0, 0, time.UTC), nil},
+		{"app timezone", tasks[1], time.Date(2023, 4, 16, 10, 30, 0, 0, time.UTC), nil},
+		{"task timezone", tasks[2], time.Date(2023, 4, 16, 2, 30, 0, 0, time.UTC), nil},
+		{"weekday", tasks[3], time.Date(2023, 4, 17, 9, 0, 0, 0, time.UTC), nil},
+		{"invalid expression", tasks[4], time.Time{}, InvalidExpressionErr},
+	}
+	for _, item := range cases {
+		t.Run(item.name, func(t *testing.T) {
+			next, err := item.task.Next(after)
+			if !errors.Is(err, item.err) {
+				t.Fatalf("err = %v, want %v", err, item.err)
+			}
+			if !next.Equal(item.expected) {
+				t.Errorf("next = %v, want %v", next, item.expected)
+			}
+		})
+	}
+}
+
+func TestDue(t *testing.T) {
+	var (
+		schedule, _ = newSchedule(miniredis.RunT(t))
+		minute      = time.Date(2023, 4, 16, 10, 30, 0, 0, time.UTC)
+	)
+	schedule.Call(func() {}).DailyAt("10:30")
+	schedule.Call(func() {}).EveryFiveSeconds()
+	schedule.Call(func() {}).DailyAt("10:31")
+	tasks := Tasks(schedule)
+
+	cases := []struct {
+		name   string
+		task   Task
+		at     time.Time
+		dueAt  bool
+		within bool
+	}{
+		{"daily at its second", tasks[0], minute, true, true},
+		{"daily later in its minute", tasks[0], minute.Add(20 * time.Second), false, true},
+		{"every five seconds", tasks[1], minute.Add(15 * time.Second), true, true},
+		{"every five seconds between ticks", tasks[1], minute.Add(16 * time.Second), false, true},
+		{"next minute", tasks[2], minute, false, false},
+	}
+	for _, item := range cases {
+		t.Run(item.name, func(t *testing.T) {
+			if due := item.task.DueAt(item.at); due != item.dueAt {
+				t.Errorf("DueAt = %v, want %v", due, item.dueAt)
+			}
+			if due := item.task.DueWithin(item.at); due != item.within {
+				t.Errorf("DueWithin = %v, want %v", due, item.within)
+			}
+		})
+	}
+}
+
+func TestRunAll(t *testing.T) {
+	var schedule, app = newSchedule(miniredis.RunT(t))
+	schedule.Call(func() { time.Sleep(20 * time.Millisecond) }).Description("slow")
+	schedule.Call(func() { panic("boom") }).Description("panics")
+	schedule.Call(func() {}).Description("skipped").When(func() bool { return false })
+
+	var results = RunAll(app, Tasks(schedule), time.Time{})
+	cases := []struct {
+		name    string
+		err     error
+		skipped bool
+	}{
+		{"slow", nil, false},
+		{"panics", TaskPanicErr, false},
+		{"skipped", TaskSkippedErr, true},
+	}
+	var names = make([]string, 0, len(results))
+	for _, result := range results {
+		names = append(names, result.Task.Name())
+	}
+	if !reflect.DeepEqual(names, []string{"slow", "panics", "skipped"}) {
+		t.Fatalf("results are in order %v", names)
+	}
+	for i, item := range cases {
+		t.Run(item.name, func(t *testing.T) {
+			if !errors.Is(results[i].Err, item.err) {
+				t.Errorf("err = %v, want %v", results[i].Err, item.err)
+			}
+			if results[i].Skipped() != item.skipped {
+				t.Errorf("skipped = %v, want %v", results[i].Skipped(), item.skipped)
+			}
+		})
+	}
+}
+
+func TestFind(t *testing.T) {
+	schedule, _ := newSchedule(miniredis.RunT(t))
+	schedule.Call(func() {}).Description("report").Daily()
+	schedule.Call(func() {}).EveryMinute()
+	tasks := Tasks(schedule)
+
+	cases := []struct {
+		key   string
+		index int
+		err   error
+	}{
+		{"report", 0, nil},
+		{"#1", 1, nil},
+		{"1", 1, nil},
+		{"#2", 0, TaskNotFoundErr},
+		{"missing", 0, TaskNotFoundErr},
+	}
+	for _, item := range cases {
+		t.Run(item.key, func(t *testing.T) {
+			task, err := Find(tasks, item.key)
+			if !errors.Is(err, item.err) {
+				t.Fatalf("err = %v, want %v", err, item.err)
+			}
+			if err == nil && task.Index != item.index {
+				t.Errorf("index = %d, want %d", task.Index, item.index)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package websocket
+
+import (
+	"github.com/goal-web/contracts"
+	"github.com/gorilla/websocket"
+	"time"
+)
+
+// Connection 关闭时会先向客户端发送关闭帧，客户端可以据此区分服务端正常关闭和网络异常
+type Connection struct {
+	contracts.WebSocketConnection
+	ws *websocket.Conn
+}
+
+func (conn *Connection) Close() error {
+	_ = conn.ws.WriteControl(
+		websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseGoingAway, "server is shutting down"),
+		time.Now().Add(time.Second),
+	)
+	return conn.WebSocketConnection.Close()
+}
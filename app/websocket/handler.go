@@ -0,0 +1,76 @@
+package websocket
+
+import (
+	"errors"
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/http"
+	"github.com/goal-web/supports/exceptions"
+	"github.com/goal-web/supports/logs"
+	websocket2 "github.com/goal-web/websocket"
+	"github.com/gorilla/websocket"
+)
+
+// New 同 websocket.New，区别是连接关闭时会发送关闭帧
+func New(controller contracts.WebSocketController) any {
+	return func(request *http.Request, serializer contracts.Serializer, socket contracts.WebSocket, config contracts.Config, handler contracts.ExceptionHandler) error {
+		var upgrader = config.Get("websocket").(websocket2.Config).Upgrader
+		var ws, err = upgrader.Upgrade(request.Context.Response(), request.Request(), nil)
+
+		if err != nil {
+			logs.WithError(err).Error("websocket.New: Upgrade failed")
+			return err
+		}
+
+		var fd = socket.GetFd()
+
+		if err = controller.OnConnect(request, fd); err != nil {
+			logs.WithError(err).Error("websocket.New: OnConnect failed")
+			return err
+		}
+
+		var conn = &Connection{WebSocketConnection: websocket2.NewConnection(ws, fd), ws: ws}
+		socket.Add(conn)
+
+		defer func() {
+			controller.OnClose(fd)
+			// 关闭期间连接已经被关闭过了
+			if closeErr := socket.Close(fd); closeErr != nil && !errors.Is(closeErr, websocket2.ConnectionDontExistsErr) {
+				logs.WithError(closeErr).Error("websocket.New: Connection close failed")
+			}
+		}()
+
+		for {
+			var msgType, msg, readErr = ws.ReadMessage()
+			if readErr != nil {
+				if websocket.IsCloseError(readErr, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+					return nil
+				}
+				logs.WithError(readErr).Error("websocket.New: Failed to read message")
+				return readErr
+			}
+
+			switch msgType {
+			case websocket.TextMessage, websocket.BinaryMessage:
+				go handleMessage(websocket2.NewFrame(msg, conn, serializer), controller, handler)
+			case websocket.CloseMessage:
+				return nil
+			}
+		}
+	}
+}
+
+// Default 同 websocket.Default
+func Default(handler func(frame contracts.WebSocketFrame)) any {
+	return New(&websocket2.DefaultController{Handler: handler})
+}
+
+func handleMessage(frame contracts.WebSocketFrame, controller contracts.WebSocketController, handler contracts.ExceptionHandler) {
+	defer func() {
+		if panicValue := recover(); panicValue != nil {
+			handler.Handle(websocket2.Exception{
+				Exception: exceptions.WithRecover(panicValue),
+			})
+		}
+	}()
+	controller.OnMessage(frame)
+}
@@ -7,12 +7,18 @@ import (
 
 var (
 	configs = make(map[string]contracts.ConfigProvider)
+	schemas = make(map[string]Schema)
 )
 
 func GetConfigProviders() map[string]contracts.ConfigProvider {
 	return configs
 }
 
+// GetSchemas 获取各配置声明的校验规则
+func GetSchemas() map[string]Schema {
+	return schemas
+}
+
 func init() {
 	configs["app"] = func(env contracts.Env) any {
 		return application.Config{
@@ -24,4 +30,9 @@ func init() {
 			Key:      env.GetString("app.key"),
 		}
 	}
+
+	schemas["app"] = Schema{
+		Key("app.key").Required(),
+		Key("app.debug").Bool(),
+	}
 }
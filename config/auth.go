@@ -41,4 +41,9 @@ func init() {
 			},
 		}
 	}
+
+	schemas["auth"] = Schema{
+		Key("auth.default").In("jwt", "session"),
+		Key("auth.jwt.secret").Required().When(Equals("auth.default", "jwt", "jwt")),
+	}
 }
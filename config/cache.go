@@ -25,4 +25,8 @@ func init() {
 			},
 		}
 	}
+
+	schemas["cache"] = Schema{
+		Key("cache.default").In("memory", "redis"),
+	}
 }
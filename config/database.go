@@ -58,4 +58,21 @@ func init() {
 			},
 		}
 	}
+
+	schemas["database"] = Schema{
+		Key("db.connection").In("sqlite", "mysql", "pgsql", "clickhouse"),
+		Key("db.sqlite.database").Required().When(Equals("db.connection", "mysql", "sqlite")),
+		Key("db.host").Required().When(func(env contracts.Env) bool {
+			return Equals("db.connection", "mysql", "mysql")(env) && env.GetString("db.unix_socket") == ""
+		}),
+		Key("db.port").Int(),
+		Key("db.database").Required().When(Equals("db.connection", "mysql", "mysql")),
+		Key("db.max_connections").Int(),
+		Key("db.max_idles").Int(),
+		Key("db.pgsql.host").Required().When(Equals("db.connection", "mysql", "pgsql")),
+		Key("db.pgsql.port").Int(),
+		Key("db.pgsql.database").Required().When(Equals("db.connection", "mysql", "pgsql")),
+		Key("db.pgsql.sslmode").In("disable", "allow", "prefer", "require", "verify-ca", "verify-full"),
+		Key("db.clickhouse.address").Required().When(Equals("db.connection", "mysql", "clickhouse")),
+	}
 }
@@ -0,0 +1,111 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Masked 替换敏感配置的占位符
+const Masked = "******"
+
+// maxDepth 防止循环引用导致无限递归
+const maxDepth = 10
+
+// sensitive 字段名（忽略大小写和下划线）包含这些词时视为敏感配置
+var sensitive = []string{"password", "secret", "token", "accesskey", "dsn"}
+
+// sensitivePaths 名称本身不敏感但需要隐藏的配置，例如 app.key
+var sensitivePaths = map[string]bool{
+	"app.key":                 true,
+	"encryption.key":          true,
+	"encryption.previouskeys": true,
+}
+
+// Dump 将配置转换为可以序列化成 json、toml 的 map、slice 和基础类型，敏感配置会被隐藏
+// 函数、通道等无法序列化的字段会被忽略
+func Dump(name string, value any) any {
+	result, _ := dump(name, reflect.ValueOf(value), 0, true)
+	return result
+}
+
+// dump 第二个返回值为 false 表示该值应该被忽略，mask 为 false 时不隐藏敏感配置
+func dump(path string, value reflect.Value, depth int, mask bool) (any, bool) {
+	if !value.IsValid() || depth > maxDepth {
+		return nil, false
+	}
+
+	if value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil, false
+		}
+		return dump(path, value.Elem(), depth+1, mask)
+	}
+
+	if mask && isSensitive(path) {
+		if value.IsZero() {
+			return "", true
+		}
+		return Masked, true
+	}
+
+	if value.Kind() != reflect.Struct && value.Kind() != reflect.String {
+		if stringer, ok := value.Interface().(fmt.Stringer); ok { // 例如 time.Duration、os.FileMode
+			return stringer.String(), true
+		}
+	}
+
+	switch value.Kind() {
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer, reflect.Uintptr:
+		return nil, false
+	case reflect.Struct:
+		var fields = make(map[string]any)
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			if item, ok := dump(path+"."+field.Name, value.Field(i), depth+1, mask); ok {
+				fields[field.Name] = item
+			}
+		}
+		return fields, true
+	case reflect.Map:
+		var fields = make(map[string]any)
+		iter := value.MapRange()
+		for iter.Next() {
+			key := fmt.Sprint(iter.Key().Interface())
+			if item, ok := dump(path+"."+key, iter.Value(), depth+1, mask); ok {
+				fields[key] = item
+			}
+		}
+		return fields, true
+	case reflect.Slice, reflect.Array:
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			return fmt.Sprintf("%s", value.Interface()), true
+		}
+		var items = make([]any, 0, value.Len())
+		for i := 0; i < value.Len(); i++ {
+			if item, ok := dump(path, value.Index(i), depth+1, mask); ok {
+				items = append(items, item)
+			}
+		}
+		return items, true
+	default:
+		return value.Interface(), true
+	}
+}
+
+func isSensitive(path string) bool {
+	if sensitivePaths[strings.ToLower(path)] {
+		return true
+	}
+	name := path[strings.LastIndex(path, ".")+1:]
+	name = strings.ToLower(strings.ReplaceAll(name, "_", ""))
+	for _, word := range sensitive {
+		if strings.Contains(name, word) {
+			return true
+		}
+	}
+	return false
+}
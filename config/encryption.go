@@ -0,0 +1,42 @@
+package config
+
+import (
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/supports/utils"
+	"strings"
+)
+
+// EncryptionConfig 应用密钥配置
+type EncryptionConfig struct {
+	Key          string   // 当前密钥，加密和签名都使用它
+	PreviousKeys []string // 轮换下来的旧密钥，只用于解密和验签
+}
+
+func init() {
+	// 应用密钥取自 app.key，轮换后旧密钥放在 app.previous_keys 中（逗号分隔），见 key:generate
+	configs["encryption"] = func(env contracts.Env) any {
+		return EncryptionConfig{
+			Key:          env.GetString("app.key"),
+			PreviousKeys: splitKeys(env.Get("app.previous_keys")),
+		}
+	}
+}
+
+// splitKeys 支持逗号分隔的字符串以及 toml 数组
+func splitKeys(value any) []string {
+	var keys = make([]string, 0)
+	switch items := value.(type) {
+	case nil:
+	case []any:
+		for _, item := range items {
+			keys = append(keys, splitKeys(item)...)
+		}
+	default:
+		for _, key := range strings.Split(utils.ToString(items, ""), ",") {
+			if key = strings.TrimSpace(key); key != "" {
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys
+}
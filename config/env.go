@@ -0,0 +1,201 @@
+package config
+
+import (
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/supports"
+	"github.com/goal-web/supports/logs"
+	"github.com/goal-web/supports/utils"
+	"github.com/joho/godotenv"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// EnvPrefix 环境变量前缀，GOAL_HTTP_PORT 对应 http.port
+const EnvPrefix = "GOAL_"
+
+type layeredEnv struct {
+	supports.BaseFields
+	dir      string
+	fields   contracts.Fields
+	problems []error
+	mutex    sync.Mutex
+}
+
+// NewEnv 合并多层配置，优先级从低到高依次为：
+//  1. config.toml
+//  2. config.<app.env>.toml，例如 config.production.toml，app.env 取自合并后的其他各层
+//  3. .env
+//  4. GOAL_ 前缀的环境变量，GOAL_HTTP_PORT 对应 http.port，已有配置项中的下划线会被识别，
+//     例如 GOAL_DB_MAX_CONNECTIONS 对应 db.max_connections，也可以用双下划线表示下划线
+//  5. 与配置项同名的环境变量，例如 http.port
+//
+// 文件不存在时跳过，同一个镜像可以只通过环境变量配置
+// 以 enc: 开头的值在合并后使用环境变量 GOAL_CONFIG_KEY 解密，见 config:encrypt
+func NewEnv(dir string) contracts.Env {
+	env := &layeredEnv{
+		BaseFields: supports.BaseFields{OptionalGetter: func(key string, defaultValue any) any {
+			if value, ok := lookupEnv(key); ok {
+				return value
+			}
+			return defaultValue
+		}},
+		dir: dir,
+	}
+
+	env.BaseFields.FieldsProvider = env
+	return env
+}
+
+func (env *layeredEnv) Fields() contracts.Fields {
+	env.mutex.Lock()
+	defer env.mutex.Unlock()
+	if env.fields == nil {
+		env.fields, env.problems = env.load()
+	}
+
+	return env.fields
+}
+
+// Problems 加载配置时遇到的问题，例如解密失败
+func (env *layeredEnv) Problems() []error {
+	env.Fields()
+
+	env.mutex.Lock()
+	defer env.mutex.Unlock()
+	return env.problems
+}
+
+// Load 重新读取各层配置
+func (env *layeredEnv) Load() contracts.Fields {
+	fields, problems := env.load()
+
+	env.mutex.Lock()
+	defer env.mutex.Unlock()
+	env.fields, env.problems = fields, problems
+
+	return fields
+}
+
+// Stage 重新读取各层配置但不替换当前配置，返回读取到的配置以及用它替换当前配置的函数
+// 热加载时先校验读取到的配置，校验不通过时当前配置保持不变
+func (env *layeredEnv) Stage() (contracts.Env, func()) {
+	candidate := NewEnv(env.dir).(*layeredEnv)
+	candidate.fields, candidate.problems = env.load()
+
+	return candidate, func() {
+		env.mutex.Lock()
+		defer env.mutex.Unlock()
+		env.fields, env.problems = candidate.fields, candidate.problems
+	}
+}
+
+func (env *layeredEnv) load() (contracts.Fields, []error) {
+	var (
+		base    = readToml(filepath.Join(env.dir, "config.toml"))
+		dotenv  = readDotEnv(filepath.Join(env.dir, ".env"))
+		environ = readEnviron(merge(base, dotenv))
+		appEnv  = utils.ToString(merge(base, dotenv, environ)["app.env"], "")
+		scoped  = make(contracts.Fields)
+	)
+
+	if appEnv != "" {
+		scoped = readToml(filepath.Join(env.dir, "config."+appEnv+".toml"))
+		environ = readEnviron(merge(base, scoped, dotenv))
+	}
+
+	fields := merge(base, scoped, dotenv, environ)
+
+	return fields, decryptFields(fields)
+}
+
+// lookupEnv 读取与配置项同名的环境变量，以 enc: 开头的值和其他各层一样解密，解密失败时返回空字符串
+func lookupEnv(key string) (string, bool) {
+	value, ok := os.LookupEnv(key)
+	if !ok || !strings.HasPrefix(value, EncryptedPrefix) {
+		return value, ok
+	}
+	encryptor, err := NewEncryptor("")
+	if err == nil {
+		value, err = Decrypt(encryptor, value)
+	}
+	if err != nil {
+		logs.WithError(fmt.Errorf("%w：%s：%v", DecryptConfigErr, key, err)).Error("config.lookupEnv: decrypt failed")
+		return "", true
+	}
+	return value, true
+}
+
+// merge 按顺序合并，后面的覆盖前面的
+func merge(layers ...contracts.Fields) contracts.Fields {
+	var fields = make(contracts.Fields)
+	for _, layer := range layers {
+		for key, value := range layer {
+			fields[key] = value
+		}
+	}
+	return fields
+}
+
+func readToml(path string) contracts.Fields {
+	var fields, results = make(contracts.Fields), make(contracts.Fields)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		logs.Default().Debug("config.readToml: " + err.Error())
+		return results
+	}
+	if err = toml.Unmarshal(content, &fields); err != nil {
+		logs.WithError(err).Error("config.readToml: " + path)
+		return results
+	}
+	utils.Flatten(results, fields, ".")
+	return results
+}
+
+func readDotEnv(path string) contracts.Fields {
+	var results = make(contracts.Fields)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		logs.Default().Debug("config.readDotEnv: " + err.Error())
+		return results
+	}
+	values, err := godotenv.UnmarshalBytes(content)
+	if err != nil {
+		logs.WithError(err).Error("config.readDotEnv: " + path)
+		return results
+	}
+	for key, value := range values {
+		results[key] = value
+	}
+	return results
+}
+
+// readEnviron 读取 GOAL_ 前缀的环境变量，known 中已有的配置项用于识别配置名中的下划线
+func readEnviron(known contracts.Fields) contracts.Fields {
+	var (
+		results = make(contracts.Fields)
+		names   = make(map[string]string)
+	)
+	for key := range known {
+		names[strings.ToUpper(strings.ReplaceAll(key, ".", "_"))] = key
+	}
+	for _, item := range os.Environ() {
+		name, value, _ := strings.Cut(item, "=")
+		if !strings.HasPrefix(name, EnvPrefix) || name == ConfigKeyEnv {
+			continue
+		}
+		name = strings.TrimPrefix(name, EnvPrefix)
+		if key, exists := names[name]; exists {
+			results[key] = value
+			continue
+		}
+		key := strings.ToLower(name)
+		key = strings.ReplaceAll(key, "__", "\x00")
+		key = strings.ReplaceAll(key, "_", ".")
+		results[strings.ReplaceAll(key, "\x00", "_")] = value
+	}
+	return results
+}
@@ -0,0 +1,125 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLayeredEnv(t *testing.T) {
+	var (
+		key          = "config-key"
+		encryptor, _ = NewEncryptor(key)
+		write        = func(dir, name, content string) {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	)
+
+	cases := []struct {
+		name     string
+		files    map[string]string
+		environ  map[string]string
+		key      string
+		expected string
+	}{
+		{
+			name:     "config.toml",
+			files:    map[string]string{"config.toml": "[http]\nport = \"8000\"\n"},
+			key:      "http.port",
+			expected: "8000",
+		},
+		{
+			name: "config.<app.env>.toml overrides config.toml",
+			files: map[string]string{
+				"config.toml":            "[app]\nenv = \"production\"\n[http]\nport = \"8000\"\n",
+				"config.production.toml": "[http]\nport = \"8001\"\n",
+			},
+			key:      "http.port",
+			expected: "8001",
+		},
+		{
+			name: "app.env from an environment variable selects the scoped file",
+			files: map[string]string{
+				"config.toml":         "[http]\nport = \"8000\"\n",
+				"config.staging.toml": "[http]\nport = \"8001\"\n",
+			},
+			environ:  map[string]string{"GOAL_APP_ENV": "staging"},
+			key:      "http.port",
+			expected: "8001",
+		},
+		{
+			name: ".env overrides config files",
+			files: map[string]string{
+				"config.toml": "[http]\nport = \"8000\"\n",
+				".env":        "http.port=8002\n",
+			},
+			key:      "http.port",
+			expected: "8002",
+		},
+		{
+			name: "GOAL_ variables override .env",
+			files: map[string]string{
+				"config.toml": "[db]\nmax_connections = 10\n",
+				".env":        "db.max_connections=20\n",
+			},
+			environ:  map[string]string{"GOAL_DB_MAX_CONNECTIONS": "30"},
+			key:      "db.max_connections",
+			expected: "30",
+		},
+		{
+			name:     "double underscores in GOAL_ variables",
+			environ:  map[string]string{"GOAL_REDIS_KEY__PREFIX": "goal:"},
+			key:      "redis.key_prefix",
+			expected: "goal:",
+		},
+		{
+			name:     "same name variables override GOAL_ variables",
+			environ:  map[string]string{"GOAL_HTTP_PORT": "8003", "http.port": "8004"},
+			key:      "http.port",
+			expected: "8004",
+		},
+		{
+			name:     "encrypted values in config files",
+			files:    map[string]string{"config.toml": "[db]\npassword = \"" + Encrypt(encryptor, "secret") + "\"\n"},
+			key:      "db.password",
+			expected: "secret",
+		},
+		{
+			name:     "encrypted GOAL_ variables",
+			environ:  map[string]string{"GOAL_DB_PASSWORD": Encrypt(encryptor, "secret")},
+			key:      "db.password",
+			expected: "secret",
+		},
+		{
+			name:     "encrypted same name variables",
+			files:    map[string]string{"config.toml": "[db]\npassword = \"plain\"\n"},
+			environ:  map[string]string{"db.password": Encrypt(encryptor, "secret")},
+			key:      "db.password",
+			expected: "secret",
+		},
+		{
+			name:     "same name variables that can not be decrypted are empty",
+			files:    map[string]string{"config.toml": "[db]\npassword = \"plain\"\n"},
+			environ:  map[string]string{"db.password": EncryptedPrefix + "!!!"},
+			key:      "db.password",
+			expected: "",
+		},
+	}
+	for _, item := range cases {
+		t.Run(item.name, func(t *testing.T) {
+			var dir = t.TempDir()
+			for name, content := range item.files {
+				write(dir, name, content)
+			}
+			t.Setenv(ConfigKeyEnv, key)
+			for name, value := range item.environ {
+				t.Setenv(name, value)
+			}
+			if value := NewEnv(dir).GetString(item.key); value != item.expected {
+				t.Errorf("%s = %q, want %q", item.key, value, item.expected)
+			}
+		})
+	}
+}
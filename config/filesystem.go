@@ -28,4 +28,9 @@ func init() {
 			},
 		}
 	}
+
+	schemas["filesystem"] = Schema{
+		Key("filesystem.driver").Required().In("local", "qiniu"),
+		Key("filesystem.root").Required().When(Equals("filesystem.driver", "", "local")),
+	}
 }
@@ -12,4 +12,8 @@ func init() {
 			Port: env.GetString("http.port"),
 		}
 	}
+
+	schemas["http"] = Schema{
+		Key("http.port").Required().Int(),
+	}
 }
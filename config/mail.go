@@ -22,4 +22,8 @@ func init() {
 			},
 		}
 	}
+
+	schemas["mail"] = Schema{
+		Key("mail.port").Int(),
+	}
 }
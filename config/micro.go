@@ -20,4 +20,8 @@ func init() {
 			},
 		}
 	}
+
+	schemas["micro"] = Schema{
+		Key("micro.etcd.address").Required(),
+	}
 }
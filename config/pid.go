@@ -0,0 +1,25 @@
+package config
+
+import (
+	"github.com/goal-web/contracts"
+	"strings"
+)
+
+// PidConfig pid 文件配置
+type PidConfig struct {
+	// Path pid 文件路径，{role} 会被替换为启动的角色，例如 storage/pids/queue-work.pid
+	Path string
+}
+
+// Role 给定角色（启动命令）的 pid 文件路径
+func (config PidConfig) Role(role string) string {
+	return strings.ReplaceAll(config.Path, "{role}", strings.ReplaceAll(role, ":", "-"))
+}
+
+func init() {
+	configs["pid"] = func(env contracts.Env) any {
+		return PidConfig{
+			Path: env.StringOptional("pid.path", "storage/pids/{role}.pid"),
+		}
+	}
+}
@@ -3,9 +3,17 @@ package config
 import (
 	"github.com/goal-web/contracts"
 	"github.com/goal-web/queue"
+	"github.com/goal-web/supports/utils"
 	"strings"
 )
 
+// LocksConfig 锁存储的配置，Store 为 redis 或者 cache，Connection 为 redis 连接名或者缓存存储名
+type LocksConfig struct {
+	Store      string
+	Connection string
+	Prefix     string
+}
+
 func init() {
 	configs["queue"] = func(env contracts.Env) any {
 		return queue.Config{
@@ -14,6 +22,21 @@ func init() {
 				Queue:      env.StringOptional("queue.default", "default"),
 			},
 			Connections: map[string]contracts.Fields{
+				"sync": { // 在 Push 时直接执行，本地开发和测试用
+					"driver": "sync",
+				},
+				"redis": { // 延迟任务保存在有序集合中，取出后超过 retry_after 秒未确认的任务会被重新取出
+					"driver":      "redis",
+					"connection":  env.GetString("queue.redis.connection"), // config/redis.go 中的连接，为空时使用默认连接
+					"prefix":      "queues:",
+					"retry_after": 90,
+				},
+				"database": { // 任务保存在 jobs 表中，执行 goal migrate 创建
+					"driver":      "database",
+					"connection":  env.GetString("queue.database.connection"), // config/database.go 中的连接，为空时使用默认连接
+					"table":       "jobs",
+					"retry_after": 90, // 取出后超过给定秒数仍未确认的任务会被重新取出，应该大于任务的超时时间
+				},
 				"default": {
 					"driver":  "kafka",
 					"delay":   "delay_queue", // 延迟队列名
@@ -52,4 +75,20 @@ func init() {
 			},
 		}
 	}
+
+	// WithoutOverlapping 任务中间件使用的锁，多个 worker 进程需要使用同一个 redis 或者共享的缓存存储
+	configs["queue_locks"] = func(env contracts.Env) any {
+		return LocksConfig{
+			Store:      utils.StringOr(env.GetString("queue.locks.store"), "redis"),
+			Connection: env.GetString("queue.locks.connection"),
+			Prefix:     utils.StringOr(env.GetString("queue.locks.prefix"), "goal:"),
+		}
+	}
+
+	schemas["queue"] = Schema{
+		Key("queue.connection").In("default", "nsq", "sync", "database", "redis"),
+		Key("queue.locks.store").In("redis", "cache"),
+		Key("queue.kafka.brokers").Required().When(Equals("queue.connection", "default", "default")),
+		Key("queue.nsq.address").Required().When(Equals("queue.connection", "default", "nsq")),
+	}
 }
@@ -0,0 +1,30 @@
+package config
+
+import (
+	"github.com/goal-web/contracts"
+)
+
+// RateLimitConfig 接口限流配置，见 middlewares.RateLimit
+type RateLimitConfig struct {
+	// Limits 限流器名 => 每秒最多处理的请求数，为 0 时不限流
+	Limits map[string]int
+}
+
+// Rate 给定限流器每秒最多处理的请求数
+func (config RateLimitConfig) Rate(name string) int {
+	return config.Limits[name]
+}
+
+func init() {
+	configs["ratelimit"] = func(env contracts.Env) any {
+		return RateLimitConfig{
+			Limits: map[string]int{
+				"api": env.IntOptional("ratelimit.api", 0),
+			},
+		}
+	}
+
+	schemas["ratelimit"] = Schema{
+		Key("ratelimit.api").Int(),
+	}
+}
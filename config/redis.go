@@ -32,4 +32,12 @@ func init() {
 			},
 		}
 	}
+
+	schemas["redis"] = Schema{
+		Key("redis.host").Required(),
+		Key("redis.port").Int(),
+		Key("redis.db").Int(),
+		Key("redis.cache.port").Int(),
+		Key("redis.cache.db").Int(),
+	}
 }
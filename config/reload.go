@@ -0,0 +1,70 @@
+package config
+
+import (
+	"github.com/goal-web/contracts"
+	"reflect"
+	"sort"
+)
+
+// Reload 重新读取配置文件和环境变量并重建各配置，返回变化的配置项，例如 mail.Mailers.default.password
+// 先读取并校验新的配置，校验不通过时不替换当前配置也不重建配置，已有的服务继续使用原来的配置
+func Reload(env contracts.Env, conf contracts.Config) ([]string, []error) {
+	before := snapshot(conf)
+
+	candidate, commit := stage(env)
+	if problems := Validate(candidate); len(problems) > 0 {
+		return nil, problems
+	}
+	commit()
+	conf.Reload()
+
+	return diff(before, snapshot(conf)), nil
+}
+
+// stage 读取新的配置，NewEnv 以外的 Env 不支持暂存，只能直接重新读取
+func stage(env contracts.Env) (contracts.Env, func()) {
+	if staged, ok := env.(interface {
+		Stage() (contracts.Env, func())
+	}); ok {
+		return staged.Stage()
+	}
+	env.Load()
+	return env, func() {}
+}
+
+// snapshot 将所有配置展开成 配置项 => 值
+func snapshot(conf contracts.Config) map[string]any {
+	var fields = make(map[string]any)
+	for name := range configs {
+		if value, ok := dump(name, reflect.ValueOf(conf.Get(name)), 0, false); ok {
+			flatten(fields, name, value)
+		}
+	}
+	return fields
+}
+
+func flatten(fields map[string]any, path string, value any) {
+	if items, isMap := value.(map[string]any); isMap {
+		for key, item := range items {
+			flatten(fields, path+"."+key, item)
+		}
+		return
+	}
+	fields[path] = value
+}
+
+func diff(before, after map[string]any) []string {
+	var changes = make([]string, 0)
+	for key, value := range after {
+		if previous, exists := before[key]; !exists || !reflect.DeepEqual(previous, value) {
+			changes = append(changes, key)
+		}
+	}
+	for key := range before {
+		if _, exists := after[key]; !exists {
+			changes = append(changes, key)
+		}
+	}
+	sort.Strings(changes)
+	return changes
+}
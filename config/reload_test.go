@@ -0,0 +1,114 @@
+package config
+
+import (
+	"github.com/goal-web/config"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const validToml = `
+[app]
+key = "test-key"
+
+[http]
+port = "8008"
+
+[auth]
+jwt.secret = "secret"
+
+[db]
+connection = "sqlite"
+sqlite.database = "test.db"
+
+[filesystem]
+driver = "local"
+root = "storage"
+
+[micro]
+etcd.address = "localhost:2379"
+
+[queue]
+connection = "sync"
+
+[redis]
+host = "127.0.0.1"
+`
+
+func TestReload(t *testing.T) {
+	var (
+		dir  = t.TempDir()
+		file = filepath.Join(dir, "config.toml")
+		save = func(content string) {
+			if err := os.WriteFile(file, []byte(validToml+content), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	)
+	save("[ratelimit]\napi = 2\n")
+	var (
+		env  = NewEnv(dir)
+		conf = config.New(env, GetConfigProviders())
+	)
+	conf.Reload()
+
+	steps := []struct {
+		name     string
+		content  string
+		changes  []string
+		problems bool
+		port     string
+		rate     int
+	}{
+		{
+			name:    "nothing changed",
+			content: "[ratelimit]\napi = 2\n",
+			changes: []string{},
+			port:    "8008",
+			rate:    2,
+		},
+		{
+			name:     "invalid values are not applied",
+			content:  "[ratelimit]\napi = 5\n[http]\nport = \"abc\"\n",
+			problems: true,
+			port:     "8008",
+			rate:     2,
+		},
+		{
+			name:    "valid values are applied",
+			content: "[ratelimit]\napi = 5\n",
+			changes: []string{"ratelimit.Limits.api"},
+			port:    "8008",
+			rate:    5,
+		},
+		{
+			name:     "a previously rejected reload does not leak in",
+			content:  "[ratelimit]\napi = \"fast\"\n",
+			problems: true,
+			port:     "8008",
+			rate:     5,
+		},
+	}
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			save(step.content)
+			changes, problems := Reload(env, conf)
+			if step.problems != (len(problems) > 0) {
+				t.Fatalf("problems = %v, want problems: %v", problems, step.problems)
+			}
+			if !step.problems && !reflect.DeepEqual(changes, step.changes) {
+				t.Errorf("changes = %v, want %v", changes, step.changes)
+			}
+			if port := env.GetString("http.port"); port != step.port {
+				t.Errorf("env http.port = %q, want %q", port, step.port)
+			}
+			if rate := env.GetInt("ratelimit.api"); rate != step.rate {
+				t.Errorf("env ratelimit.api = %d, want %d", rate, step.rate)
+			}
+			if rate := conf.Get("ratelimit").(RateLimitConfig).Rate("api"); rate != step.rate {
+				t.Errorf("config ratelimit = %d, want %d", rate, step.rate)
+			}
+		})
+	}
+}
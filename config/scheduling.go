@@ -0,0 +1,33 @@
+package config
+
+import (
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/supports/utils"
+)
+
+// SchedulingConfig 任务调度配置
+type SchedulingConfig struct {
+	// Locks WithoutOverlapping、OnOneServer 使用的锁
+	Locks LocksConfig
+
+	// HistoryConnection 记录执行历史的数据库连接，为空时使用默认连接
+	HistoryConnection string
+}
+
+func init() {
+	configs["scheduling"] = func(env contracts.Env) any {
+		return SchedulingConfig{
+			// 多个调度实例需要使用同一个 redis 或者共享的缓存存储
+			Locks: LocksConfig{
+				Store:      utils.StringOr(env.GetString("scheduling.store"), "redis"),
+				Connection: env.GetString("scheduling.connection"),
+				Prefix:     utils.StringOr(env.GetString("scheduling.prefix"), "goal:"),
+			},
+			HistoryConnection: env.GetString("scheduling.history_connection"),
+		}
+	}
+
+	schemas["scheduling"] = Schema{
+		Key("scheduling.store").In("redis", "cache"),
+	}
+}
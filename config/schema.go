@@ -0,0 +1,140 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/supports/utils"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var InvalidConfigErr = errors.New("配置有误")
+
+// Schema 配置的校验规则，和配置一起在 init 中声明
+type Schema []*Rule
+
+const (
+	kindString = "string"
+	kindInt    = "int"
+	kindBool   = "bool"
+)
+
+// Rule 单个配置项的校验规则
+type Rule struct {
+	key      string
+	kind     string
+	required bool
+	allowed  []string
+	when     func(env contracts.Env) bool
+}
+
+// Key 声明配置项，默认为可选的字符串
+func Key(key string) *Rule {
+	return &Rule{key: key, kind: kindString}
+}
+
+// Required 配置项不能为空
+func (rule *Rule) Required() *Rule {
+	rule.required = true
+	return rule
+}
+
+// Int 配置项必须是整数
+func (rule *Rule) Int() *Rule {
+	rule.kind = kindInt
+	return rule
+}
+
+// Bool 配置项必须是布尔值
+func (rule *Rule) Bool() *Rule {
+	rule.kind = kindBool
+	return rule
+}
+
+// In 配置项只能是给定值之一
+func (rule *Rule) In(values ...string) *Rule {
+	rule.allowed = values
+	return rule
+}
+
+// When 仅在满足条件时校验，例如只在使用 mysql 连接时要求 db.host
+func (rule *Rule) When(condition func(env contracts.Env) bool) *Rule {
+	rule.when = condition
+	return rule
+}
+
+// Equals 配置项（为空时取 defaultValue）等于给定值之一，配合 When 使用
+func Equals(key, defaultValue string, values ...string) func(env contracts.Env) bool {
+	return func(env contracts.Env) bool {
+		return oneOf(env.StringOptional(key, defaultValue), values)
+	}
+}
+
+// validate 返回该配置项的问题，没有问题时返回 nil
+func (rule *Rule) validate(env contracts.Env) error {
+	if rule.when != nil && !rule.when(env) {
+		return nil
+	}
+
+	var value = env.Get(rule.key)
+	if value == nil || utils.ToString(value, "") == "" {
+		if rule.required {
+			return fmt.Errorf("%w：%s 不能为空", InvalidConfigErr, rule.key)
+		}
+		return nil
+	}
+
+	var str = utils.ToString(value, "")
+	switch rule.kind {
+	case kindInt:
+		if _, err := strconv.ParseInt(str, 10, 64); err != nil {
+			return fmt.Errorf("%w：%s 必须是整数，当前为 %q", InvalidConfigErr, rule.key, str)
+		}
+	case kindBool:
+		if _, err := strconv.ParseBool(str); err != nil {
+			return fmt.Errorf("%w：%s 必须是布尔值，当前为 %q", InvalidConfigErr, rule.key, str)
+		}
+	}
+
+	if len(rule.allowed) > 0 && !oneOf(str, rule.allowed) {
+		return fmt.Errorf("%w：%s 只能是 %s 之一，当前为 %q", InvalidConfigErr, rule.key, strings.Join(rule.allowed, "、"), str)
+	}
+
+	return nil
+}
+
+// Validate 按各配置声明的规则校验，返回所有问题，包括加载配置时遇到的问题
+func Validate(env contracts.Env) []error {
+	var (
+		names    = make([]string, 0, len(schemas))
+		problems = make([]error, 0)
+	)
+	if loaded, ok := env.(interface{ Problems() []error }); ok {
+		problems = append(problems, loaded.Problems()...)
+	}
+	for name := range schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		for _, rule := range schemas[name] {
+			if err := rule.validate(env); err != nil {
+				problems = append(problems, err)
+			}
+		}
+	}
+
+	return problems
+}
+
+func oneOf(value string, values []string) bool {
+	for _, item := range values {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/goal/app/keyring"
+	"os"
+	"strings"
+)
+
+var (
+	MissingConfigKeyErr = errors.New("缺少配置密钥，请设置环境变量 " + ConfigKeyEnv)
+	DecryptConfigErr    = errors.New("配置解密失败")
+	WrongConfigKeyErr   = errors.New("配置密钥不正确")
+)
+
+const (
+	// EncryptedPrefix 加密配置的前缀，例如 password = "enc:xxxx"
+	EncryptedPrefix = "enc:"
+
+	// ConfigKeyEnv 解密配置所用密钥的环境变量，格式与 app.key 相同，可以用 key:generate --show 生成
+	ConfigKeyEnv = EnvPrefix + "CONFIG_KEY"
+)
+
+// NewEncryptor 使用给定密钥创建加密器（AES-256-GCM，与应用密钥相同），密钥为空时使用环境变量 GOAL_CONFIG_KEY
+func NewEncryptor(key string) (contracts.Encryptor, error) {
+	if key == "" {
+		key = os.Getenv(ConfigKeyEnv)
+	}
+	if key == "" {
+		return nil, MissingConfigKeyErr
+	}
+
+	encryptor, err := keyring.New(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w：%v", DecryptConfigErr, err)
+	}
+	return encryptor, nil
+}
+
+// Encrypt 加密配置值，返回带 enc: 前缀的密文
+func Encrypt(encryptor contracts.Encryptor, value string) string {
+	return EncryptedPrefix + encryptor.Encode(value)
+}
+
+// Decrypt 解密配置值，没有 enc: 前缀的值原样返回
+func Decrypt(encryptor contracts.Encryptor, value string) (string, error) {
+	if !strings.HasPrefix(value, EncryptedPrefix) {
+		return value, nil
+	}
+	result, err := encryptor.Decode(strings.TrimPrefix(value, EncryptedPrefix))
+	if err != nil { // GCM 会校验密文，密钥不对或者密文被改动时都会解密失败
+		return "", WrongConfigKeyErr
+	}
+	return result, nil
+}
+
+// decryptFields 解密所有带 enc: 前缀的配置，解密失败的配置置为空并返回错误
+func decryptFields(fields contracts.Fields) []error {
+	var (
+		problems  = make([]error, 0)
+		encryptor contracts.Encryptor
+		keyErr    error
+	)
+	for key, value := range fields {
+		str, isString := value.(string)
+		if !isString || !strings.HasPrefix(str, EncryptedPrefix) {
+			continue
+		}
+		if encryptor == nil && keyErr == nil {
+			encryptor, keyErr = NewEncryptor("")
+		}
+
+		fields[key] = ""
+		if keyErr != nil {
+			problems = append(problems, fmt.Errorf("%w：%s：%v", DecryptConfigErr, key, keyErr))
+			continue
+		}
+		plaintext, err := Decrypt(encryptor, str)
+		if err != nil {
+			problems = append(problems, fmt.Errorf("%w：%s：%v", DecryptConfigErr, key, err))
+			continue
+		}
+		fields[key] = plaintext
+	}
+	return problems
+}
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDecrypt(t *testing.T) {
+	encryptor, err := NewEncryptor("config-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wrong, err := NewEncryptor("wrong-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted := Encrypt(encryptor, "secret")
+
+	cases := []struct {
+		name     string
+		value    string
+		expected string
+		err      error
+	}{
+		{"encrypted", encrypted, "secret", nil},
+		{"plain value", "secret", "secret", nil},
+		{"not base64", EncryptedPrefix + "!!!", "", WrongConfigKeyErr},
+		{"tampered", encrypted[:len(encrypted)-4] + "AAAA", "", WrongConfigKeyErr},
+	}
+	for _, item := range cases {
+		t.Run(item.name, func(t *testing.T) {
+			value, err := Decrypt(encryptor, item.value)
+			if !errors.Is(err, item.err) {
+				t.Fatalf("err = %v, want %v", err, item.err)
+			}
+			if value != item.expected {
+				t.Errorf("value = %q, want %q", value, item.expected)
+			}
+		})
+	}
+
+	t.Run("wrong key", func(t *testing.T) {
+		if _, err := Decrypt(wrong, encrypted); !errors.Is(err, WrongConfigKeyErr) {
+			t.Errorf("err = %v, want %v", err, WrongConfigKeyErr)
+		}
+	})
+	t.Run("missing key", func(t *testing.T) {
+		t.Setenv(ConfigKeyEnv, "")
+		if _, err := NewEncryptor(""); !errors.Is(err, MissingConfigKeyErr) {
+			t.Errorf("err = %v, want %v", err, MissingConfigKeyErr)
+		}
+	})
+}
@@ -19,4 +19,8 @@ func init() {
 			Name:       env.StringOptional("session.name", "goal"),
 		}
 	}
+
+	schemas["session"] = Schema{
+		Key("session.lifetime").Int(),
+	}
 }
@@ -0,0 +1,24 @@
+package config
+
+import (
+	"github.com/goal-web/contracts"
+	"time"
+)
+
+// ShutdownConfig 优雅关闭配置
+type ShutdownConfig struct {
+	Timeout time.Duration // 优雅关闭的最长等待时间，超时后直接关闭各服务
+}
+
+func init() {
+	configs["shutdown"] = func(env contracts.Env) any {
+		return ShutdownConfig{
+			// 收到退出信号后等待请求、任务、长连接排空的最长时间，单位秒
+			Timeout: time.Duration(env.IntOptional("shutdown.timeout", 30)) * time.Second,
+		}
+	}
+
+	schemas["shutdown"] = Schema{
+		Key("shutdown.timeout").Int(),
+	}
+}
@@ -0,0 +1,48 @@
+package factories
+
+import (
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/goal/app/models"
+)
+
+// Articles 文章工厂，没有指定作者时创建文章前会先创建一个 blogger
+func Articles() Factory[models.Article] {
+	return Factory[models.Article]{
+		definition: func(faker *Faker) models.Article {
+			return models.Article{
+				Title:   faker.Sentence(faker.Int(3, 8)),
+				Content: faker.Paragraph(faker.Int(2, 5)),
+			}
+		},
+		fields: func(article models.Article) contracts.Fields {
+			fields := contracts.Fields{
+				"user_id": article.UserId,
+				"title":   article.Title,
+				"content": article.Content,
+			}
+			if article.Id != "" {
+				fields["id"] = article.Id
+			}
+			return fields
+		},
+		query: models.ArticleQuery,
+		count: 1,
+		beforeCreating: []func(article *models.Article, connection string) error{
+			func(article *models.Article, connection string) error {
+				if article.UserId != "" {
+					return nil
+				}
+				author, err := Users().State(Blogger).Connection(connection).Create()
+				article.UserId = author.Id
+				return err
+			},
+		},
+	}
+}
+
+// ForUser 指定文章的作者
+func ForUser(user models.User) func(article *models.Article) {
+	return func(article *models.Article) {
+		article.UserId = user.Id
+	}
+}
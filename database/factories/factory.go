@@ -0,0 +1,106 @@
+package factories
+
+import (
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/database/table"
+)
+
+// Factory 模型工厂，Make 只生成结构体，Create 会写入数据库
+// 方法都返回新的工厂，可以放心地在基础工厂上组合不同的状态
+type Factory[T any] struct {
+	definition     func(faker *Faker) T
+	fields         func(model T) contracts.Fields
+	query          func() *table.Table[T]
+	states         []func(model *T)
+	beforeCreating []func(model *T, connection string) error
+	afterCreating  []func(model T, connection string) error
+	count          int
+	connection     string
+}
+
+// State 修改生成的模型，例如 Users().State(Blogger)
+func (factory Factory[T]) State(states ...func(model *T)) Factory[T] {
+	factory.states = append(append([]func(model *T){}, factory.states...), states...)
+	return factory
+}
+
+// BeforeCreating 写入数据库之前执行，例如先创建关联的模型
+func (factory Factory[T]) BeforeCreating(callbacks ...func(model *T, connection string) error) Factory[T] {
+	factory.beforeCreating = append(append([]func(model *T, connection string) error{}, factory.beforeCreating...), callbacks...)
+	return factory
+}
+
+// AfterCreating 写入数据库之后执行，此时模型已经有 id，例如 Users().AfterCreating(WithArticles(3))
+func (factory Factory[T]) AfterCreating(callbacks ...func(model T, connection string) error) Factory[T] {
+	factory.afterCreating = append(append([]func(model T, connection string) error{}, factory.afterCreating...), callbacks...)
+	return factory
+}
+
+// Count MakeMany、CreateMany 生成的数量
+func (factory Factory[T]) Count(count int) Factory[T] {
+	factory.count = count
+	return factory
+}
+
+// Connection 写入的数据库连接，默认为 config/database.go 中的默认连接
+func (factory Factory[T]) Connection(connection string) Factory[T] {
+	factory.connection = connection
+	return factory
+}
+
+// Make 生成一个模型，不写入数据库
+func (factory Factory[T]) Make() T {
+	model := factory.definition(NewFaker())
+	for _, state := range factory.states {
+		state(&model)
+	}
+	return model
+}
+
+// MakeMany 生成 Count 个模型，不写入数据库
+func (factory Factory[T]) MakeMany() []T {
+	var models = make([]T, 0, factory.count)
+	for i := 0; i < factory.count; i++ {
+		models = append(models, factory.Make())
+	}
+	return models
+}
+
+// Create 生成一个模型并写入数据库，返回带 id 的模型
+func (factory Factory[T]) Create() (T, error) {
+	model := factory.Make()
+	for _, callback := range factory.beforeCreating {
+		if err := callback(&model, factory.connection); err != nil {
+			return model, err
+		}
+	}
+
+	query := factory.query()
+	if factory.connection != "" {
+		query.SetConnection(factory.connection)
+	}
+	created, exception := query.CreateE(factory.fields(model))
+	if exception != nil {
+		return model, exception
+	}
+
+	for _, callback := range factory.afterCreating {
+		if err := callback(*created, factory.connection); err != nil {
+			return *created, err
+		}
+	}
+	return *created, nil
+}
+
+// CreateMany 生成 Count 个模型并写入数据库
+func (factory Factory[T]) CreateMany() ([]T, error) {
+	var models = make([]T, 0, factory.count)
+	for i := 0; i < factory.count; i++ {
+		model, err := factory.Create()
+		if err != nil {
+			return models, err
+		}
+		models = append(models, model)
+	}
+	return models, nil
+}
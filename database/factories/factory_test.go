@@ -0,0 +1,157 @@
+package factories
+
+import (
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/database/drivers"
+	"github.com/goal-web/database/table"
+	"github.com/goal-web/goal/app/migration"
+	"github.com/goal-web/goal/app/models"
+	"github.com/goal-web/goal/database/migrations"
+	"path/filepath"
+	"testing"
+)
+
+// connections 按名称返回临时 sqlite 连接的 DBFactory，空名称为 default
+type connections struct {
+	contracts.DBFactory
+	items map[string]contracts.DBConnection
+}
+
+func (factory connections) Connection(key ...string) contracts.DBConnection {
+	if len(key) == 0 || key[0] == "" {
+		return factory.items["default"]
+	}
+	return factory.items[key[0]]
+}
+
+// useDatabases 为给定的连接名创建执行过迁移的 sqlite 数据库
+func useDatabases(t *testing.T, names ...string) connections {
+	t.Helper()
+	var factory = connections{items: map[string]contracts.DBConnection{}}
+	for _, name := range names {
+		conn := drivers.SqliteConnector(contracts.Fields{"database": filepath.Join(t.TempDir(), name+".db")}, nil)
+		migrator, err := migration.NewMigrator(conn, migrations.All())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = migrator.Run(false); err != nil {
+			t.Fatal(err)
+		}
+		factory.items[name] = conn
+	}
+	table.SetFactory(factory)
+	t.Cleanup(func() { table.SetFactory(nil) })
+	return factory
+}
+
+func count(t *testing.T, conn contracts.DBConnection, query string, args ...any) int {
+	t.Helper()
+	var counts = make([]int, 0)
+	if err := conn.Select(&counts, query, args...); err != nil {
+		t.Fatal(err)
+	}
+	return counts[0]
+}
+
+func TestMake(t *testing.T) {
+	var (
+		base     = Users()
+		bloggers = base.State(Blogger)
+		renamed  = bloggers.State(func(user *models.User) { user.NickName = "goal" })
+	)
+	if user := base.Make(); user.Role != "user" || user.NickName == "" || user.Id != "" {
+		t.Errorf("Make = %+v, want a user without id", user)
+	}
+	if user := renamed.Make(); user.Role != "blogger" || user.NickName != "goal" {
+		t.Errorf("Make with states = %+v", user)
+	}
+	if user := bloggers.Make(); user.NickName == "goal" {
+		t.Error("State changed the factory it was called on")
+	}
+	if users := base.Count(3).MakeMany(); len(users) != 3 {
+		t.Errorf("MakeMany made %d users, want 3", len(users))
+	}
+	if article := Articles().State(ForUser(models.User{Id: "7"})).Make(); article.UserId != "7" || article.Title == "" || article.Content == "" {
+		t.Errorf("article = %+v", article)
+	}
+}
+
+func TestCreate(t *testing.T) {
+	var conn = useDatabases(t, "default").items["default"]
+
+	user, err := Users().State(Blogger).AfterCreating(WithArticles(2)).Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Id == "" || user.Role != "blogger" {
+		t.Errorf("created %+v, want a blogger with id", user)
+	}
+	if articles := count(t, conn, "select count(*) from articles where user_id = ?", user.Id); articles != 2 {
+		t.Errorf("articles = %d, want 2", articles)
+	}
+
+	// 没有指定作者时先创建一个 blogger
+	article, err := Articles().Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if article.UserId == "" || article.UserId == user.Id {
+		t.Errorf("article author = %q, want a new user", article.UserId)
+	}
+	if bloggers := count(t, conn, "select count(*) from users where role = 'blogger'"); bloggers != 2 {
+		t.Errorf("bloggers = %d, want 2", bloggers)
+	}
+
+	users, err := Users().Count(3).CreateMany()
+	if err != nil || len(users) != 3 {
+		t.Fatalf("CreateMany = %d users, %v", len(users), err)
+	}
+	if total := count(t, conn, "select count(*) from users"); total != 5 {
+		t.Errorf("users = %d, want 5", total)
+	}
+}
+
+func TestCreateOnConnection(t *testing.T) {
+	var factory = useDatabases(t, "default", "secondary")
+
+	if _, err := Users().AfterCreating(WithArticles(1)).Connection("secondary").Create(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Articles().Connection("secondary").Create(); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		connection string
+		users      int
+		articles   int
+	}{
+		{"default", 0, 0},
+		{"secondary", 2, 2},
+	}
+	for _, item := range cases {
+		t.Run(item.connection, func(t *testing.T) {
+			conn := factory.items[item.connection]
+			if users := count(t, conn, "select count(*) from users"); users != item.users {
+				t.Errorf("users = %d, want %d", users, item.users)
+			}
+			if articles := count(t, conn, "select count(*) from articles"); articles != item.articles {
+				t.Errorf("articles = %d, want %d", articles, item.articles)
+			}
+		})
+	}
+}
+
+func TestCreateFails(t *testing.T) {
+	var conn = useDatabases(t, "default").items["default"]
+	if _, err := conn.Exec("drop table articles"); err != nil {
+		t.Fatal(err)
+	}
+	users, err := Users().AfterCreating(WithArticles(1)).Count(3).CreateMany()
+	if err == nil {
+		t.Fatal("CreateMany without articles table succeeded")
+	}
+	if len(users) != 0 {
+		t.Errorf("CreateMany returned %d users, the one whose callback failed is not returned", len(users))
+	}
+}
@@ -0,0 +1,69 @@
+package factories
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+var (
+	firstNames = []string{"James", "Mary", "Robert", "Linda", "Michael", "Sarah", "David", "Emma", "Daniel", "Olivia", "Wei", "Fang", "Jun", "Li", "Hui"}
+	lastNames  = []string{"Smith", "Johnson", "Brown", "Garcia", "Miller", "Davis", "Wilson", "Wang", "Zhang", "Chen", "Liu", "Huang"}
+	words      = strings.Fields("lorem ipsum dolor sit amet consectetur adipiscing elit sed do eiusmod tempor incididunt ut labore et dolore magna aliqua enim ad minim veniam quis nostrud exercitation ullamco laboris nisi aliquip ex ea commodo consequat")
+)
+
+// Faker 生成假数据
+type Faker struct {
+	rand *rand.Rand
+}
+
+func NewFaker() *Faker {
+	return &Faker{rand: rand.New(rand.NewSource(time.Now().UnixNano()))}
+}
+
+// Pick 随机选择一个
+func (faker *Faker) Pick(items ...string) string {
+	return items[faker.rand.Intn(len(items))]
+}
+
+// Int [min, max] 之间的随机整数
+func (faker *Faker) Int(min, max int) int {
+	return min + faker.rand.Intn(max-min+1)
+}
+
+func (faker *Faker) Bool() bool {
+	return faker.rand.Intn(2) == 1
+}
+
+func (faker *Faker) Name() string {
+	return faker.Pick(firstNames...) + " " + faker.Pick(lastNames...)
+}
+
+// Email 带随机后缀，避免唯一索引冲突
+func (faker *Faker) Email() string {
+	return fmt.Sprintf("%s.%d@example.com", strings.ToLower(faker.Pick(firstNames...)), faker.rand.Intn(1000000))
+}
+
+// Sentence words 个单词组成的句子，首字母大写
+func (faker *Faker) Sentence(words int) string {
+	sentence := faker.words(words)
+	return strings.ToUpper(sentence[:1]) + sentence[1:] + "."
+}
+
+// Paragraph sentences 个句子组成的段落
+func (faker *Faker) Paragraph(sentences int) string {
+	var items = make([]string, 0, sentences)
+	for i := 0; i < sentences; i++ {
+		items = append(items, faker.Sentence(faker.Int(6, 12)))
+	}
+	return strings.Join(items, " ")
+}
+
+func (faker *Faker) words(count int) string {
+	var items = make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		items = append(items, faker.Pick(words...))
+	}
+	return strings.Join(items, " ")
+}
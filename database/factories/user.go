@@ -0,0 +1,47 @@
+package factories
+
+import (
+	"encoding/json"
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/goal/app/models"
+)
+
+// Users 用户工厂，默认为普通用户
+func Users() Factory[models.User] {
+	return Factory[models.User]{
+		definition: func(faker *Faker) models.User {
+			return models.User{
+				NickName: faker.Name(),
+				Role:     "user",
+				Settings: models.Settings{XxxSwitch: faker.Bool()},
+			}
+		},
+		fields: func(user models.User) contracts.Fields {
+			settings, _ := json.Marshal(user.Settings)
+			fields := contracts.Fields{
+				"name":     user.NickName,
+				"role":     user.Role,
+				"settings": string(settings),
+			}
+			if user.Id != "" {
+				fields["id"] = user.Id
+			}
+			return fields
+		},
+		query: models.UserQuery,
+		count: 1,
+	}
+}
+
+// Blogger 可以发布文章的用户，见 policies.Article
+func Blogger(user *models.User) {
+	user.Role = "blogger"
+}
+
+// WithArticles 创建用户后为其创建 count 篇文章
+func WithArticles(count int, states ...func(article *models.Article)) func(user models.User, connection string) error {
+	return func(user models.User, connection string) error {
+		_, err := Articles().State(ForUser(user)).State(states...).Connection(connection).Count(count).CreateMany()
+		return err
+	}
+}
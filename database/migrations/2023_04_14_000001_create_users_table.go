@@ -0,0 +1,21 @@
+package migrations
+
+import "github.com/goal-web/goal/app/migration"
+
+func init() {
+	migrations = append(migrations, migration.Migration{
+		Name: "2023_04_14_000001_create_users_table",
+		Up: func(schema *migration.Schema) error {
+			return schema.Create("users", func(table *migration.Blueprint) {
+				table.ID()
+				table.String("name")
+				table.String("role", 50).Default("")
+				table.Json("settings").Nullable()
+				table.Timestamps()
+			})
+		},
+		Down: func(schema *migration.Schema) error {
+			return schema.Drop("users")
+		},
+	})
+}
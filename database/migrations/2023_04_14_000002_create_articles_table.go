@@ -0,0 +1,22 @@
+package migrations
+
+import "github.com/goal-web/goal/app/migration"
+
+func init() {
+	migrations = append(migrations, migration.Migration{
+		Name: "2023_04_14_000002_create_articles_table",
+		Up: func(schema *migration.Schema) error {
+			return schema.Create("articles", func(table *migration.Blueprint) {
+				table.ID()
+				table.BigInteger("user_id")
+				table.String("title")
+				table.Text("content")
+				table.Timestamps()
+				table.Index("user_id")
+			})
+		},
+		Down: func(schema *migration.Schema) error {
+			return schema.Drop("articles")
+		},
+	})
+}
@@ -0,0 +1,23 @@
+package migrations
+
+import "github.com/goal-web/goal/app/migration"
+
+// 死信表，表名见 config/queue.go 中的 Failed.Table
+func init() {
+	migrations = append(migrations, migration.Migration{
+		Name: "2023_04_14_000003_create_failed_jobs_table",
+		Up: func(schema *migration.Schema) error {
+			return schema.Create("failed_jobs", func(table *migration.Blueprint) {
+				table.ID()
+				table.Text("connection")
+				table.Text("queue")
+				table.Text("payload")
+				table.Text("exception")
+				table.Timestamp("failed_at").Default(migration.Expression("CURRENT_TIMESTAMP"))
+			})
+		},
+		Down: func(schema *migration.Schema) error {
+			return schema.Drop("failed_jobs")
+		},
+	})
+}
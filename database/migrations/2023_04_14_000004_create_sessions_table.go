@@ -0,0 +1,22 @@
+package migrations
+
+import "github.com/goal-web/goal/app/migration"
+
+// session 表，表名见 config/session.go 中的 Table
+func init() {
+	migrations = append(migrations, migration.Migration{
+		Name: "2023_04_14_000004_create_sessions_table",
+		Up: func(schema *migration.Schema) error {
+			return schema.Create("sessions", func(table *migration.Blueprint) {
+				table.String("id").Primary()
+				table.BigInteger("user_id").Nullable()
+				table.Text("payload")
+				table.Integer("last_activity")
+				table.Index("last_activity")
+			})
+		},
+		Down: func(schema *migration.Schema) error {
+			return schema.Drop("sessions")
+		},
+	})
+}
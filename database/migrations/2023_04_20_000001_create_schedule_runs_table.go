@@ -0,0 +1,26 @@
+package migrations
+
+import "github.com/goal-web/goal/app/migration"
+
+// 调度任务的执行历史，表名见 app/scheduling 中的 HistoryTable
+func init() {
+	migrations = append(migrations, migration.Migration{
+		Name: "2023_04_20_000001_create_schedule_runs_table",
+		Up: func(schema *migration.Schema) error {
+			return schema.Create("schedule_runs", func(table *migration.Blueprint) {
+				table.ID()
+				table.String("task")
+				table.String("expression")
+				table.Timestamp("started_at")
+				table.BigInteger("duration") // 毫秒
+				table.String("outcome", 16)
+				table.Text("message").Nullable()
+				table.Index("task")
+				table.Index("started_at")
+			})
+		},
+		Down: func(schema *migration.Schema) error {
+			return schema.Drop("schedule_runs")
+		},
+	})
+}
@@ -0,0 +1,25 @@
+package migrations
+
+import "github.com/goal-web/goal/app/migration"
+
+// database 队列驱动的任务表，表名见 config/queue.go 中 database 连接的 table
+func init() {
+	migrations = append(migrations, migration.Migration{
+		Name: "2023_04_21_000001_create_jobs_table",
+		Up: func(schema *migration.Schema) error {
+			return schema.Create("jobs", func(table *migration.Blueprint) {
+				table.ID()
+				table.String("queue")
+				table.Text("payload")
+				table.Integer("attempts").Default(0)
+				table.BigInteger("reserved_at").Nullable() // unix 时间戳，秒
+				table.BigInteger("available_at")
+				table.BigInteger("created_at")
+				table.Index("queue", "reserved_at", "available_at")
+			})
+		},
+		Down: func(schema *migration.Schema) error {
+			return schema.Drop("jobs")
+		},
+	})
+}
@@ -0,0 +1,11 @@
+package migrations
+
+import "github.com/goal-web/goal/app/migration"
+
+// migrations 各迁移文件在 init 中注册，文件名与迁移名一致
+var migrations = make([]migration.Migration, 0)
+
+// All 所有迁移，执行顺序由迁移名决定
+func All() []migration.Migration {
+	return migrations
+}
@@ -0,0 +1,17 @@
+package seeders
+
+import "github.com/goal-web/goal/database/factories"
+
+// ArticleSeeder 三个 blogger，每人五篇文章
+type ArticleSeeder struct {
+}
+
+func (seeder ArticleSeeder) Run(connection string) error {
+	_, err := factories.Users().
+		State(factories.Blogger).
+		AfterCreating(factories.WithArticles(5)).
+		Connection(connection).
+		Count(3).
+		CreateMany()
+	return err
+}
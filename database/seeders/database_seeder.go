@@ -0,0 +1,9 @@
+package seeders
+
+// DatabaseSeeder db:seed 默认执行的填充类
+type DatabaseSeeder struct {
+}
+
+func (seeder DatabaseSeeder) Run(connection string) error {
+	return Call(connection, UserSeeder{}, ArticleSeeder{})
+}
@@ -0,0 +1,41 @@
+package seeders
+
+import "sort"
+
+// Seeder 填充数据，connection 为 db:seed 指定的连接，为空时使用默认连接
+type Seeder interface {
+	Run(connection string) error
+}
+
+// seeders db:seed --class 可以指定的填充类
+var seeders = map[string]Seeder{
+	"DatabaseSeeder": DatabaseSeeder{},
+	"UserSeeder":     UserSeeder{},
+	"ArticleSeeder":  ArticleSeeder{},
+}
+
+// Find 按名称查找填充类
+func Find(name string) (Seeder, bool) {
+	seeder, exists := seeders[name]
+	return seeder, exists
+}
+
+// Names 所有填充类的名称
+func Names() []string {
+	var names = make([]string, 0, len(seeders))
+	for name := range seeders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Call 依次执行填充类
+func Call(connection string, items ...Seeder) error {
+	for _, seeder := range items {
+		if err := seeder.Run(connection); err != nil {
+			return err
+		}
+	}
+	return nil
+}
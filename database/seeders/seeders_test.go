@@ -0,0 +1,116 @@
+package seeders
+
+import (
+	"errors"
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/database/drivers"
+	"github.com/goal-web/database/table"
+	"github.com/goal-web/goal/app/migration"
+	"github.com/goal-web/goal/database/migrations"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// connection 总是返回同一个临时 sqlite 连接的 DBFactory
+type connection struct {
+	contracts.DBFactory
+	conn contracts.DBConnection
+}
+
+func (factory connection) Connection(...string) contracts.DBConnection {
+	return factory.conn
+}
+
+func useDatabase(t *testing.T) contracts.DBConnection {
+	t.Helper()
+	conn := drivers.SqliteConnector(contracts.Fields{"database": filepath.Join(t.TempDir(), "test.db")}, nil)
+	migrator, err := migration.NewMigrator(conn, migrations.All())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = migrator.Run(false); err != nil {
+		t.Fatal(err)
+	}
+	table.SetFactory(connection{conn: conn})
+	t.Cleanup(func() { table.SetFactory(nil) })
+	return conn
+}
+
+func count(t *testing.T, conn contracts.DBConnection, query string) int {
+	t.Helper()
+	var counts = make([]int, 0)
+	if err := conn.Select(&counts, query); err != nil {
+		t.Fatal(err)
+	}
+	return counts[0]
+}
+
+func TestSeeders(t *testing.T) {
+	cases := []struct {
+		name     string
+		seeder   Seeder
+		users    int
+		bloggers int
+		articles int
+	}{
+		{"UserSeeder", UserSeeder{}, 10, 0, 0},
+		{"ArticleSeeder", ArticleSeeder{}, 3, 3, 15},
+		{"DatabaseSeeder", DatabaseSeeder{}, 13, 3, 15},
+	}
+	for _, item := range cases {
+		t.Run(item.name, func(t *testing.T) {
+			conn := useDatabase(t)
+			if err := item.seeder.Run(""); err != nil {
+				t.Fatal(err)
+			}
+			if users := count(t, conn, "select count(*) from users"); users != item.users {
+				t.Errorf("users = %d, want %d", users, item.users)
+			}
+			if bloggers := count(t, conn, "select count(*) from users where role = 'blogger'"); bloggers != item.bloggers {
+				t.Errorf("bloggers = %d, want %d", bloggers, item.bloggers)
+			}
+			if articles := count(t, conn, "select count(*) from articles"); articles != item.articles {
+				t.Errorf("articles = %d, want %d", articles, item.articles)
+			}
+		})
+	}
+}
+
+// seederFunc 记录执行顺序的填充类
+type seederFunc func(connection string) error
+
+func (seeder seederFunc) Run(connection string) error {
+	return seeder(connection)
+}
+
+func TestCall(t *testing.T) {
+	var (
+		failed = errors.New("failed")
+		called = make([]string, 0)
+		record = func(name string, err error) Seeder {
+			return seederFunc(func(connection string) error {
+				called = append(called, name+"@"+connection)
+				return err
+			})
+		}
+	)
+	if err := Call("secondary", record("a", nil), record("b", failed), record("c", nil)); !errors.Is(err, failed) {
+		t.Errorf("err = %v, want %v", err, failed)
+	}
+	if expected := []string{"a@secondary", "b@secondary"}; !reflect.DeepEqual(called, expected) {
+		t.Errorf("called = %v, want %v", called, expected)
+	}
+}
+
+func TestFind(t *testing.T) {
+	if _, exists := Find("DatabaseSeeder"); !exists {
+		t.Error("DatabaseSeeder not found")
+	}
+	if _, exists := Find("Missing"); exists {
+		t.Error("found a seeder that does not exist")
+	}
+	if names := Names(); !reflect.DeepEqual(names, []string{"ArticleSeeder", "DatabaseSeeder", "UserSeeder"}) {
+		t.Errorf("names = %v", names)
+	}
+}
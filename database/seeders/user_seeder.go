@@ -0,0 +1,12 @@
+package seeders
+
+import "github.com/goal-web/goal/database/factories"
+
+// UserSeeder 十个普通用户
+type UserSeeder struct {
+}
+
+func (seeder UserSeeder) Run(connection string) error {
+	_, err := factories.Users().Connection(connection).Count(10).CreateMany()
+	return err
+}
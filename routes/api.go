@@ -4,15 +4,18 @@ import (
 	"github.com/goal-web/auth"
 	"github.com/goal-web/contracts"
 	"github.com/goal-web/goal/app/http/controllers"
+	"github.com/goal-web/goal/app/http/middlewares"
 )
 
 func Api(router contracts.Router) {
 
 	router.Post("/queue", controllers.DemoJob)
 
-	router.Get("/", controllers.HelloWorld)
+	router.Get("/", controllers.HelloWorld, middlewares.RateLimit("api"))
+	router.Get("/healthz", controllers.Healthz)
+	router.Get("/readyz", controllers.Readyz)
 	router.Get("/micro", controllers.RpcService)
-	//router.Get("/", controllers.HelloWorld, ratelimiter.Middleware(100))
+	router.Get("/signed", controllers.HelloWorld, middlewares.ValidSignature)
 	router.Post("/login", controllers.LoginExample)
 
 	router.Get("/myself", controllers.GetCurrentUser, auth.Guard("jwt"))
@@ -21,4 +24,12 @@ func Api(router contracts.Router) {
 	authRouter.Get("/myself", controllers.GetCurrentUser, auth.Guard("jwt"))
 
 	router.Post("/mail", controllers.SendEmail)
+
+	failedJobs := router.Group("/queue/failed", auth.Guard("jwt"))
+	failedJobs.Get("", controllers.FailedJobs)
+	failedJobs.Get("/:id", controllers.FailedJob)
+	failedJobs.Post("/retry", controllers.RetryFailedJobs)
+	failedJobs.Post("/flush", controllers.FlushFailedJobs)
+	failedJobs.Delete("", controllers.ForgetFailedJobs)
+	failedJobs.Delete("/:id", controllers.ForgetFailedJobs)
 }
@@ -0,0 +1,25 @@
+package routes
+
+import "github.com/goal-web/contracts"
+
+// Collector 一类路由，Type 为 http、ws 或者 sse
+type Collector struct {
+	Type    string
+	Collect func(router contracts.Router)
+}
+
+// Collectors http 服务挂载的所有路由，route:list 也从这里读取
+var Collectors = []Collector{
+	{Type: "http", Collect: Api},
+	{Type: "ws", Collect: WebSocket},
+	{Type: "sse", Collect: Sse},
+}
+
+// All 所有路由收集函数，用于 providers.NewHttp
+func All() []any {
+	var collectors = make([]any, 0, len(Collectors))
+	for _, collector := range Collectors {
+		collectors = append(collectors, collector.Collect)
+	}
+	return collectors
+}